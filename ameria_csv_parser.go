@@ -3,8 +3,10 @@ package main
 import (
 	"bytes"
 	"encoding/csv"
+	"errors"
 	"fmt"
 	"io"
+	"log"
 	"os"
 	"strings"
 	"time"
@@ -36,7 +38,10 @@ type AmeriaBusinessTransaction struct {
 	Details             string
 }
 
-type AmeriaCsvFileParser struct{}
+type AmeriaCsvFileParser struct {
+	// Currency of the account used to parse amounts with its precision. File doesn't contain it.
+	Currency string
+}
 
 func (p AmeriaCsvFileParser) ParseRawTransactionsFromFile(
 	filePath string,
@@ -46,25 +51,37 @@ func (p AmeriaCsvFileParser) ParseRawTransactionsFromFile(
 		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
 	defer file.Close()
+	return p.ParseRawTransactions(filePath, file)
+}
+
+// ParseRawTransactions implements ReaderParser.
+func (p AmeriaCsvFileParser) ParseRawTransactions(name string, reader io.Reader) ([]Transaction, error) {
 
 	// Read the file into a byte slice
-	fileData, err := io.ReadAll(file)
+	fileData, err := io.ReadAll(reader)
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("failed to read '%s': %w", name, err)
 	}
 
-	// Convert UTF-16 to UTF-8
-	utf8Data, err := decodeUTF16ToUTF8(fileData)
+	// Convert to UTF-8 from the encoding file was saved in.
+	utf8Data, encoding, err := decodeText(fileData)
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("failed to decode '%s': %w", name, err)
 	}
+	firstLine, _, _ := strings.Cut(string(utf8Data), "\n")
+	delimiter, ok := detectAmeriaCsvDelimiter(firstLine)
+	if !ok {
+		delimiter = '\t' // Report wrong header below.
+	}
+	log.Printf("%s: reading as %s text with %q delimiter.", name, encoding, delimiter)
 
-	reader := csv.NewReader(bytes.NewReader(utf8Data))
-	reader.Comma = '\t'      // Assuming the CSV is tab-delimited
-	reader.LazyQuotes = true // Allow the reader to handle bare quotes
+	csvReader := csv.NewReader(bytes.NewReader(utf8Data))
+	csvReader.Comma = delimiter    // Tab for files from the bank, other ones after re-saving in Excel
+	csvReader.LazyQuotes = true    // Allow the reader to handle bare quotes
+	csvReader.FieldsPerRecord = -1 // Number of fields is checked for each row below
 
 	// Read the header row
-	header, err := reader.Read()
+	header, err := csvReader.Read()
 	if err != nil {
 		return nil, fmt.Errorf("failed to read header: %w", err)
 	}
@@ -75,38 +92,79 @@ func (p AmeriaCsvFileParser) ParseRawTransactionsFromFile(
 	}
 
 	// Validate header
+	if len(header) < len(csvHeaders) {
+		return nil, fmt.Errorf("unexpected header: got %d columns %v, want %v", len(header), header, csvHeaders)
+	}
 	for i, h := range csvHeaders {
 		if strings.TrimSpace(strings.Trim(header[i], `"`)) != h {
 			return nil, fmt.Errorf("unexpected header: got %s, want %s", header[i], h)
 		}
 	}
 
-	// Parse transactions
+	// Parse transactions, skip wrong rows with diagnostics.
+	lines := strings.Split(string(utf8Data), "\n")
+	rawLine := func(line int) string {
+		if line < 1 || line > len(lines) {
+			return ""
+		}
+		return strings.TrimRight(lines[line-1], "\r")
+	}
+	var diagnostics Diagnostics
+	if extra := nonEmptyValues(header[len(csvHeaders):]); len(extra) > 0 {
+		diagnostics = append(diagnostics, newRowWarning(name, 1, rawLine(1), "unexpected extra columns %v", extra))
+	}
 	var csvTransactions []AmeriaBusinessTransaction
 	for {
-		record, err := reader.Read()
-		if err != nil {
+		record, err := csvReader.Read()
+		if err == io.EOF {
 			break
 		}
+		if err != nil {
+			line := 0
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				line = parseErr.StartLine
+			}
+			diagnostics = append(diagnostics, newRowDiagnostic(name, line, rawLine(line), "failed to read row: %v", err))
+			continue
+		}
+		line, _ := csvReader.FieldPos(0)
+		raw := rawLine(line)
+		if len(record) < len(csvHeaders) {
+			diagnostics = append(diagnostics, newRowDiagnostic(name, line, raw,
+				"row has %d columns, want %d", len(record), len(csvHeaders)))
+			continue
+		}
 
 		// Strip quotes from each field
 		for i := range record {
 			record[i] = strings.Trim(record[i], `"`)
 		}
+		if extra := nonEmptyValues(record[len(csvHeaders):]); len(extra) > 0 {
+			diagnostics = append(diagnostics, newRowWarning(name, line, raw, "unexpected values in extra columns %v", extra))
+		}
 
 		// Parse date
 		date, err := time.Parse(AmeriaBusinessDateFormat, record[0])
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse date: %w", err)
+			diagnostics = append(diagnostics, newRowDiagnostic(name, line, raw, "failed to parse date: %v", err))
+			continue
 		}
 
 		// Parse credit and debit
-		var credit, debit MoneyWith2DecimalPlaces
-		if err := credit.UnmarshalText([]byte(record[4])); err != nil {
-			return nil, fmt.Errorf("failed to parse credit: %w", err)
+		credit, err := parseMoney(record[4], p.Currency)
+		if err != nil {
+			diagnostics = append(diagnostics, newRowDiagnostic(name, line, raw, "failed to parse credit: %v", err))
+			continue
+		}
+		debit, err := parseMoney(record[5], p.Currency)
+		if err != nil {
+			diagnostics = append(diagnostics, newRowDiagnostic(name, line, raw, "failed to parse debit: %v", err))
+			continue
 		}
-		if err := debit.UnmarshalText([]byte(record[5])); err != nil {
-			return nil, fmt.Errorf("failed to parse debit: %w", err)
+
+		if credit.int == 0 && debit.int == 0 {
+			diagnostics = append(diagnostics, newRowWarning(name, line, raw, "zero amount"))
 		}
 
 		transaction := AmeriaBusinessTransaction{
@@ -138,8 +196,71 @@ func (p AmeriaCsvFileParser) ParseRawTransactionsFromFile(
 			Date:      transaction.Date,
 			Details:   transaction.Details,
 			Amount:    amount,
+			Account:   transaction.Account,
 		}
 	}
 
+	if len(diagnostics) > 0 {
+		return transactions, diagnostics
+	}
 	return transactions, nil
 }
+
+// nonEmptyValues returns values which are not blank.
+func nonEmptyValues(values []string) []string {
+	result := []string{}
+	for _, value := range values {
+		if strings.TrimSpace(strings.Trim(value, `"`)) != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// isAmeriaCsvHeader checks that header row contains all expected columns.
+func isAmeriaCsvHeader(header []string) bool {
+	if len(header) < len(csvHeaders) {
+		return false
+	}
+	for i, h := range csvHeaders {
+		if strings.TrimSpace(strings.Trim(strings.TrimSpace(header[i]), `"`)) != h {
+			return false
+		}
+	}
+	return true
+}
+
+// ameriaCsvDelimiters are delimiters of Ameria CSV files. Bank uses tab, Excel uses comma or
+// semicolon depending on locale.
+var ameriaCsvDelimiters = []rune{'\t', ';', ','}
+
+// detectAmeriaCsvDelimiter returns delimiter which splits the line into Ameria headers.
+func detectAmeriaCsvDelimiter(firstLine string) (rune, bool) {
+	firstLine = strings.TrimRight(firstLine, "\r")
+	for _, delimiter := range ameriaCsvDelimiters {
+		if isAmeriaCsvHeader(strings.Split(firstLine, string(delimiter))) {
+			return delimiter, true
+		}
+	}
+	return 0, false
+}
+
+// sniffAmeriaCsv checks that file is a text in any supported encoding with Ameria headers.
+func sniffAmeriaCsv(head []byte, _ func() ([]byte, error)) bool {
+	// Cut the end of the head to don't break the last character.
+	if bytes.HasPrefix(head, bomUTF16LE) || bytes.HasPrefix(head, bomUTF16BE) {
+		head = head[:len(head)/2*2]
+	} else if index := bytes.LastIndexByte(head, '\n'); index >= 0 {
+		head = head[:index]
+	}
+	utf8Data, _, err := decodeText(head)
+	if err != nil {
+		return false
+	}
+	firstLine, _, _ := strings.Cut(string(utf8Data), "\n")
+	_, ok := detectAmeriaCsvDelimiter(firstLine)
+	return ok
+}
+
+var _ FileParser = AmeriaCsvFileParser{}
+var _ ReaderParser = AmeriaCsvFileParser{}
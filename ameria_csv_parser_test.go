@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+func TestAmeriaCsvFileParser_ParseRawTransactions(t *testing.T) {
+	// Arrange
+	content := "\ufeff" + strings.Join([]string{
+		`"Date"	"Transaction Type"	"Doc.No."	"Account"	"Credit"	"Debit"	"Remitter/Beneficiary"	"Details"`,
+		`"01/08/2023"	"Transfer"	"1"	"1570000000000000"	"1,000.00"	"0.00"	"EMPLOYER LLC"	"Salary"`,
+		`"05/08/2023"	"Card"	"2"	"1570000000000000"	"0.00"	"499.50"	"SHOP"	"Purchase"`,
+	}, "\r\n")
+
+	// Act
+	actual, err := AmeriaCsvFileParser{}.ParseRawTransactions("export.csv", bytes.NewReader(encodeUTF16LE(content)))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("ParseRawTransactions() failed: %v", err)
+	}
+	expected := []Transaction{
+		{
+			IsExpense: false,
+			Date:      time.Date(2023, time.August, 1, 0, 0, 0, 0, time.UTC),
+			Details:   "Salary",
+			Amount:    MoneyWith2DecimalPlaces{10000000},
+			Account:   "1570000000000000",
+		},
+		{
+			IsExpense: true,
+			Date:      time.Date(2023, time.August, 5, 0, 0, 0, 0, time.UTC),
+			Details:   "Purchase",
+			Amount:    MoneyWith2DecimalPlaces{4995000},
+			Account:   "1570000000000000",
+		},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("ParseRawTransactions() = %v, want %v", actual, expected)
+	}
+}
+
+func TestAmeriaCsvFileParser_ParseRawTransactionsDiagnostics(t *testing.T) {
+	// Arrange
+	content := "\ufeff" + strings.Join([]string{
+		`"Date"	"Transaction Type"	"Doc.No."	"Account"	"Credit"	"Debit"	"Remitter/Beneficiary"	"Details"`,
+		`"32/08/2023"	"Transfer"	"1"	"1570000000000000"	"1,000.00"	"0.00"	"EMPLOYER LLC"	"Salary"`,
+		`"03/08/2023"	"Card"	"2"	"1570000000000000"`,
+		`"04/08/2023"	"Card"	"3"	"1570000000000000"	"0.00"	"a lot"	"SHOP"	"Purchase"`,
+		`"05/08/2023"	"Card"	"4"	"1570000000000000"	"0.00"	"499.50"	"SHOP"	"Purchase"`,
+		`"06/08/2023"	"Card"	"5"	"1570000000000000"	"0.00"	"0.00"	"SHOP"	"Hold"`,
+		`"07/08/2023"	"Card"	"6"	"1570000000000000"	"0.00"	"1.00"	"SHOP"	"Purchase"	"New column"	""`,
+	}, "\r\n")
+
+	// Act
+	actual, err := AmeriaCsvFileParser{}.ParseRawTransactions("export.csv", bytes.NewReader(encodeUTF16LE(content)))
+
+	// Assert
+	var diagnostics Diagnostics
+	if !errors.As(err, &diagnostics) {
+		t.Fatalf("Expected diagnostics, got %v", err)
+	}
+	expected := []struct {
+		severity string
+		line     int
+		message  string
+	}{
+		{SeverityError, 2, "failed to parse date"},
+		{SeverityError, 3, "row has 4 columns, want 8"},
+		{SeverityError, 4, "failed to parse debit"},
+		{SeverityWarning, 6, "zero amount"},
+		{SeverityWarning, 7, "unexpected values in extra columns [New column]"},
+	}
+	if len(diagnostics) != len(expected) {
+		t.Fatalf("Expected %d diagnostics, got %v", len(expected), diagnostics)
+	}
+	for i, e := range expected {
+		if diagnostics[i].Severity != e.severity || diagnostics[i].Line != e.line ||
+			!strings.Contains(diagnostics[i].Message, e.message) {
+			t.Errorf("Expected %s diagnostic about '%s' in %d line, got %+v", e.severity, e.message, e.line, diagnostics[i])
+		}
+		if !strings.Contains(diagnostics[i].Raw, `"1570000000000000"`) {
+			t.Errorf("Expected raw row in diagnostic, got %+v", diagnostics[i])
+		}
+	}
+	if len(actual) != 3 || actual[0].Amount.int != 4995000 {
+		t.Errorf("Expected the last 3 rows to be parsed, got %v", actual)
+	}
+}
+
+func TestAmeriaCsvFileParser_ParseRawTransactionsEncodings(t *testing.T) {
+	rows := func(delimiter, details string) string {
+		return strings.Join([]string{
+			strings.Join(csvHeaders, delimiter),
+			strings.Join([]string{"01/08/2023", "Card", "1", "1570000000000000", "0.00", "499.50", "SHOP", details}, delimiter),
+		}, "\r\n") + "\r\n"
+	}
+	windows1251, err := charmap.Windows1251.NewEncoder().Bytes([]byte(rows(";", russianText)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name            string
+		content         []byte
+		expectedDetails string
+	}{
+		{"utf8", []byte(rows(",", armenianText)), armenianText},
+		{"utf8_bom", []byte("\ufeff" + rows(";", armenianText)), armenianText},
+		{"utf16le_bom", encodeUTF16LE("\ufeff" + rows("\t", armenianText)), armenianText},
+		{"utf16be_bom", encodeUTF16BE("\ufeff" + rows("\t", armenianText)), armenianText},
+		{"windows1251", windows1251, russianText},
+		{"armscii8", encodeArmscii8(t, rows("\t", armenianText)), armenianText},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// Act
+			actual, err := AmeriaCsvFileParser{}.ParseRawTransactions(tt.name+".csv", bytes.NewReader(tt.content))
+
+			// Assert
+			if err != nil {
+				t.Fatalf("ParseRawTransactions() failed: %v", err)
+			}
+			if len(actual) != 1 || actual[0].Details != tt.expectedDetails || actual[0].Amount.int != 4995000 {
+				t.Errorf("ParseRawTransactions() = %v, want 1 transaction with '%s' details", actual, tt.expectedDetails)
+			}
+		})
+	}
+}
+
+func TestAmeriaCsvFileParser_ParseRawTransactionsErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  []byte
+		expected string
+	}{
+		{"empty", []byte{}, "failed to read header"},
+		{"short_header", []byte("Date\tTransaction Type\r\n"), "unexpected header"},
+		{"wrong_header", encodeUTF16LE("\ufeff" + strings.Repeat("Column\t", 8)), "unexpected header"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// Act
+			_, err := AmeriaCsvFileParser{}.ParseRawTransactions(tt.name, bytes.NewReader(tt.content))
+
+			// Assert
+			if err == nil {
+				t.Fatal("Expected error, but got no error")
+			}
+			checkErrorContainsSubstring(t, err, tt.expected)
+		})
+	}
+}
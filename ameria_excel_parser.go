@@ -1,25 +1,17 @@
 package main
 
 import (
+	"bytes"
 	"fmt"
+	"io"
+	"log"
 	"slices"
-	"strconv"
 	"strings"
 	"time"
 
 	"github.com/tealeg/xlsx"
 )
 
-func (m *MoneyWith2DecimalPlaces) UnmarshalText(text []byte) error {
-	sanitizedText := strings.Replace(string(text), ",", "", -1)
-	floatVal, err := strconv.ParseFloat(sanitizedText, 64)
-	if err != nil {
-		return err
-	}
-	m.int = int(floatVal * 100)
-	return nil
-}
-
 const MyAmeriaDateFormat = "02/01/2006"
 const giveUpFindHeaderAfterEmpty1Cells = 15
 
@@ -65,14 +57,33 @@ func (p MyAmeriaExcelFileParser) ParseRawTransactionsFromFile(
 	if err != nil {
 		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
+	return p.parseXlsxFile(filePath, f)
+}
+
+// ParseRawTransactions implements ReaderParser. Reads the whole XLSX file into memory
+// because it is a ZIP archive which can't be read sequentially.
+func (p MyAmeriaExcelFileParser) ParseRawTransactions(name string, reader io.Reader) ([]Transaction, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read '%s': %w", name, err)
+	}
+	f, err := xlsx.OpenBinary(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open '%s' as XLSX file: %w", name, err)
+	}
+	return p.parseXlsxFile(name, f)
+}
+
+func (p MyAmeriaExcelFileParser) parseXlsxFile(filePath string, f *xlsx.File) ([]Transaction, error) {
 
 	// Find first sheet.
 	firstSheet := f.Sheets[0]
-	fmt.Printf("%s: parsing first sheet '%s', total %d sheets.\n",
+	log.Printf("%s: parsing first sheet '%s', total %d sheets.\n",
 		filePath, firstSheet.Name, len(f.Sheets))
 
 	// Parse myAmeriaTransactions.
 	var myAmeriaTransactions []MyAmeriaTransaction
+	var diagnostics Diagnostics
 	var isHeaderRowFound bool
 	for i, row := range firstSheet.Rows {
 		cells := row.Cells
@@ -91,15 +102,12 @@ func (p MyAmeriaExcelFileParser) ParseRawTransactionsFromFile(
 					filePath, i, xlsxHeaders,
 				)
 			}
-			var isCellMatches = true
-			for cellIndex, header := range xlsxHeaders {
-				if strings.TrimSpace(cells[cellIndex].String()) != header {
-					isCellMatches = false
-					break
-				}
-			}
-			if isCellMatches {
+			if isMyAmeriaHeaderRow(cells) {
 				isHeaderRowFound = true
+				if extra := nonEmptyValues(xlsxCellValues(cells[len(xlsxHeaders):])); len(extra) > 0 {
+					diagnostics = append(diagnostics, newRowWarning(filePath, i+1, xlsxRowToString(cells),
+						"unexpected extra columns %v", extra))
+				}
 			}
 
 			// Skip this row anyway.
@@ -111,14 +119,26 @@ func (p MyAmeriaExcelFileParser) ParseRawTransactionsFromFile(
 			break
 		}
 
-		// Parse date and amount.
+		// Parse date and amount, skip row with diagnostic if they are wrong.
 		date, err := time.Parse(MyAmeriaDateFormat, cells[0].String())
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse date from 1st cell of %d row: %w", i, err)
+			diagnostics = append(diagnostics, newRowDiagnostic(filePath, i+1, xlsxRowToString(cells),
+				"failed to parse date from 1st cell: %v", err))
+			continue
+		}
+		amount, err := parseMoney(cells[9].String(), cells[10].String())
+		if err != nil {
+			diagnostics = append(diagnostics, newRowDiagnostic(filePath, i+1, xlsxRowToString(cells),
+				"failed to parse amount from 10th cell: %v", err))
+			continue
+		}
+
+		if amount.int == 0 {
+			diagnostics = append(diagnostics, newRowWarning(filePath, i+1, xlsxRowToString(cells), "zero amount"))
 		}
-		var amount MoneyWith2DecimalPlaces
-		if err := amount.UnmarshalText([]byte(cells[9].String())); err != nil {
-			return nil, fmt.Errorf("failed to parse amount from 10th cell of %d row: %w", i, err)
+		if extra := nonEmptyValues(xlsxCellValues(cells[len(xlsxHeaders):])); len(extra) > 0 {
+			diagnostics = append(diagnostics, newRowWarning(filePath, i+1, xlsxRowToString(cells),
+				"unexpected values in extra columns %v", extra))
 		}
 
 		transaction := MyAmeriaTransaction{
@@ -141,9 +161,14 @@ func (p MyAmeriaExcelFileParser) ParseRawTransactionsFromFile(
 	transactions := make([]Transaction, len(myAmeriaTransactions))
 	for i, transaction := range myAmeriaTransactions {
 		isExpense := true
+		account := transaction.OutgoingAccount
+		receiverAccount := transaction.BeneficiaryAccount
 		if len(p.MyAccounts) > 0 {
 			if slices.Contains(p.MyAccounts, transaction.BeneficiaryAccount) {
 				isExpense = false
+				// Income goes to my account which is "beneficiary" one.
+				account = transaction.BeneficiaryAccount
+				receiverAccount = transaction.OutgoingAccount
 			}
 		} else if len(p.DetailsIncomeSubstrings) > 0 {
 			for _, substring := range p.DetailsIncomeSubstrings {
@@ -154,12 +179,71 @@ func (p MyAmeriaExcelFileParser) ParseRawTransactionsFromFile(
 			}
 		}
 		transactions[i] = Transaction{
-			IsExpense: isExpense,
-			Date:      transaction.Date,
-			Details:   transaction.Details,
-			Amount:    transaction.Amount,
+			IsExpense:       isExpense,
+			Date:            transaction.Date,
+			Details:         transaction.Details,
+			Amount:          transaction.Amount,
+			Account:         account,
+			ReceiverAccount: receiverAccount,
 		}
 	}
 
+	if len(diagnostics) > 0 {
+		return transactions, diagnostics
+	}
 	return transactions, nil
 }
+
+// xlsxCellValues returns values of cells as strings.
+func xlsxCellValues(cells []*xlsx.Cell) []string {
+	values := make([]string, len(cells))
+	for i, cell := range cells {
+		values[i] = cell.String()
+	}
+	return values
+}
+
+// xlsxRowToString returns values of cells separated by tabs.
+func xlsxRowToString(cells []*xlsx.Cell) string {
+	return strings.Join(xlsxCellValues(cells), "\t")
+}
+
+// isMyAmeriaHeaderRow checks that row contains headers of MyAmeria "History" file.
+func isMyAmeriaHeaderRow(cells []*xlsx.Cell) bool {
+	if len(cells) < len(xlsxHeaders) {
+		return false
+	}
+	for cellIndex, header := range xlsxHeaders {
+		if strings.TrimSpace(cells[cellIndex].String()) != header {
+			return false
+		}
+	}
+	return true
+}
+
+// sniffMyAmeriaExcel checks that file is XLSX (ZIP archive) with MyAmeria "History" headers.
+func sniffMyAmeriaExcel(head []byte, readAll func() ([]byte, error)) bool {
+	if !bytes.HasPrefix(head, []byte("PK\x03\x04")) {
+		return false
+	}
+	data, err := readAll()
+	if err != nil {
+		return false
+	}
+	f, err := xlsx.OpenBinary(data)
+	if err != nil || len(f.Sheets) == 0 {
+		return false
+	}
+	for i, row := range f.Sheets[0].Rows {
+		if i > giveUpFindHeaderAfterEmpty1Cells {
+			break
+		}
+		if isMyAmeriaHeaderRow(row.Cells) {
+			return true
+		}
+	}
+	return false
+}
+
+var _ FileParser = MyAmeriaExcelFileParser{}
+var _ ReaderParser = MyAmeriaExcelFileParser{}
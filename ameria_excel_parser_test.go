@@ -1,58 +1,14 @@
 package main
 
 import (
+	"bytes"
 	"path/filepath"
 	"reflect"
 	"testing"
 	"time"
-)
-
-func TestMoneyWith2DecimalPlaces_UnmarshalText(t *testing.T) {
-	tests := []struct {
-		name    string
-		input   string
-		wantInt int
-		wantErr bool
-	}{
-		{
-			name:    "valid input",
-			input:   "123.45",
-			wantInt: 12345,
-			wantErr: false,
-		},
-		{
-			name:    "input with decimal places",
-			input:   "123.456",
-			wantInt: 12345,
-			wantErr: false,
-		},
-		{
-			name:    "input with negative value",
-			input:   "-123.45",
-			wantInt: -12345,
-			wantErr: false,
-		},
-		{
-			name:    "invalid input",
-			input:   "abc",
-			wantInt: 0,
-			wantErr: true,
-		},
-	}
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			var m MoneyWith2DecimalPlaces
-			err := m.UnmarshalText([]byte(tt.input))
-			if (err != nil) != tt.wantErr {
-				t.Fatalf("unexpected error: got %v, wantErr %v", err, tt.wantErr)
-			}
-			if err == nil && m.int != tt.wantInt {
-				t.Errorf("got int %d, want %d", m.int, tt.wantInt)
-			}
-		})
-	}
-}
+	"github.com/tealeg/xlsx"
+)
 
 func TestParseRawTransactionsFromFile(t *testing.T) {
 	tests := []struct {
@@ -71,16 +27,20 @@ func TestParseRawTransactionsFromFile(t *testing.T) {
 			wantErr:       false,
 			expectedResult: []Transaction{
 				{
-					IsExpense: true,
-					Date:      time.Date(2024, time.April, 20, 0, 0, 0, 0, time.UTC),
-					Details:   "ԱԱՀ այդ թվում` 16.67%",
-					Amount:    MoneyWith2DecimalPlaces{int: 10010},
+					IsExpense:       true,
+					Date:            time.Date(2024, time.April, 20, 0, 0, 0, 0, time.UTC),
+					Details:         "ԱԱՀ այդ թվում` 16.67%",
+					Amount:          MoneyWith2DecimalPlaces{int: 1001000},
+					Account:         "1234567890123456",
+					ReceiverAccount: "9999999999999999",
 				},
 				Transaction{
-					IsExpense: false,
-					Date:      time.Date(2024, time.April, 19, 0, 0, 0, 0, time.UTC),
-					Details:   "Բանկի ձևանմուշից տարբերվող տեղեկա",
-					Amount:    MoneyWith2DecimalPlaces{int: 99999999999},
+					IsExpense:       false,
+					Date:            time.Date(2024, time.April, 19, 0, 0, 0, 0, time.UTC),
+					Details:         "Բանկի ձևանմուշից տարբերվող տեղեկա",
+					Amount:          MoneyWith2DecimalPlaces{int: 9999999999900},
+					Account:         "1234567890123456",
+					ReceiverAccount: "9999999999999999",
 				},
 			},
 		},
@@ -116,16 +76,20 @@ func TestParseRawTransactionsFromFile(t *testing.T) {
 			wantErr:       false,
 			expectedResult: []Transaction{
 				{
-					IsExpense: true,
-					Date:      time.Date(2024, time.April, 20, 0, 0, 0, 0, time.UTC),
-					Details:   "ԱԱՀ այդ թվում` 16.67%",
-					Amount:    MoneyWith2DecimalPlaces{int: 10010},
+					IsExpense:       true,
+					Date:            time.Date(2024, time.April, 20, 0, 0, 0, 0, time.UTC),
+					Details:         "ԱԱՀ այդ թվում` 16.67%",
+					Amount:          MoneyWith2DecimalPlaces{int: 1001000},
+					Account:         "1234567890123456",
+					ReceiverAccount: "9999999999999999",
 				},
 				{
-					IsExpense: true, // I.e. recognition didn't work.
-					Date:      time.Date(2024, time.April, 19, 0, 0, 0, 0, time.UTC),
-					Details:   "Բանկի ձևանմուշից տարբերվող տեղեկա",
-					Amount:    MoneyWith2DecimalPlaces{int: 99999999999},
+					IsExpense:       true, // I.e. recognition didn't work.
+					Date:            time.Date(2024, time.April, 19, 0, 0, 0, 0, time.UTC),
+					Details:         "Բանկի ձևանմուշից տարբերվող տեղեկա",
+					Amount:          MoneyWith2DecimalPlaces{int: 9999999999900},
+					Account:         "9999999999999999",
+					ReceiverAccount: "1234567890123456",
 				},
 			},
 		},
@@ -147,3 +111,57 @@ func TestParseRawTransactionsFromFile(t *testing.T) {
 		})
 	}
 }
+
+func TestMyAmeriaExcelFileParser_ParseRawTransactions(t *testing.T) {
+	// Arrange
+	file := xlsx.NewFile()
+	sheet, err := file.AddSheet("History")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, values := range [][]string{
+		{"Account history", "", "", "", "", "", "", "", "", "", ""},
+		xlsxHeaders,
+		{"20/04/2024", "1", "", "1234567890123456", "9999999999999999", "SHOP", "Purchase", "", "", "100.10", "AMD"},
+		{"19/04/2024", "2", "", "9999999999999999", "1234567890123456", "EMPLOYER", "Salary", "", "", "1,000.00", "AMD"},
+	} {
+		row := sheet.AddRow()
+		for _, value := range values {
+			row.AddCell().SetString(value)
+		}
+	}
+	var buf bytes.Buffer
+	if err := file.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	parser := MyAmeriaExcelFileParser{MyAccounts: []string{"1234567890123456"}}
+
+	// Act
+	actual, err := parser.ParseRawTransactions("History.xls", &buf)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("ParseRawTransactions() failed: %v", err)
+	}
+	expected := []Transaction{
+		{
+			IsExpense:       true,
+			Date:            time.Date(2024, time.April, 20, 0, 0, 0, 0, time.UTC),
+			Details:         "Purchase",
+			Amount:          MoneyWith2DecimalPlaces{int: 1001000},
+			Account:         "1234567890123456",
+			ReceiverAccount: "9999999999999999",
+		},
+		{
+			IsExpense:       false,
+			Date:            time.Date(2024, time.April, 19, 0, 0, 0, 0, time.UTC),
+			Details:         "Salary",
+			Amount:          MoneyWith2DecimalPlaces{int: 10000000},
+			Account:         "1234567890123456",
+			ReceiverAccount: "9999999999999999",
+		},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("ParseRawTransactions() = %v, want %v", actual, expected)
+	}
+}
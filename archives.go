@@ -0,0 +1,199 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// maxContainerDepth limits nesting of containers like ZIP archive attached to email.
+const maxContainerDepth = 3
+
+// sourceFile is a file to parse. It is either a file on disk or
+// an entry of some container (archive or email) read into memory.
+type sourceFile struct {
+	// Name is a path of file on disk or path of container joined with the entry name.
+	Name string
+	// Data is a content of container entry, nil for files on disk.
+	Data []byte
+	// IsEntry is true for entries of containers.
+	IsEntry bool
+}
+
+// isContainerFile checks by extension that file contains other files.
+func isContainerFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".zip", ".eml", ".mbox":
+		return true
+	}
+	return false
+}
+
+// readContainerEntries returns files from ZIP archive, email (.eml) or mailbox (.mbox) file.
+// Nested containers are expanded as well.
+func readContainerEntries(name string, data []byte) ([]sourceFile, error) {
+	return readContainerEntriesWithDepth(name, data, 0)
+}
+
+func readContainerEntriesWithDepth(name string, data []byte, depth int) ([]sourceFile, error) {
+	if depth >= maxContainerDepth {
+		return nil, fmt.Errorf("'%s' is nested too deep", name)
+	}
+	var entries []sourceFile
+	var err error
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".zip":
+		entries, err = readZipEntries(name, data)
+	case ".eml":
+		entries, err = readEmailEntries(name, bytes.NewReader(data))
+	case ".mbox":
+		entries, err = readMboxEntries(name, data)
+	default:
+		return nil, fmt.Errorf("'%s' is not a supported container", name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	result := []sourceFile{}
+	for _, entry := range entries {
+		if !isContainerFile(entry.Name) {
+			result = append(result, entry)
+			continue
+		}
+		nested, err := readContainerEntriesWithDepth(entry.Name, entry.Data, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, nested...)
+	}
+	return result, nil
+}
+
+// readZipEntries returns all files from ZIP archive.
+func readZipEntries(name string, data []byte) ([]sourceFile, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("can't read '%s' ZIP archive: %w", name, err)
+	}
+	entries := []sourceFile{}
+	for _, file := range archive.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		reader, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("can't open '%s' in '%s': %w", file.Name, name, err)
+		}
+		content, err := io.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			return nil, fmt.Errorf("can't read '%s' in '%s': %w", file.Name, name, err)
+		}
+		entries = append(entries, sourceFile{Name: name + "/" + file.Name, Data: content, IsEntry: true})
+	}
+	return entries, nil
+}
+
+// readEmailEntries returns attachments of the email.
+func readEmailEntries(name string, reader io.Reader) ([]sourceFile, error) {
+	message, err := mail.ReadMessage(reader)
+	if err != nil {
+		return nil, fmt.Errorf("can't read '%s' email: %w", name, err)
+	}
+	entries := []sourceFile{}
+	err = readMimePart(name, message.Header, message.Body, &entries)
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// mimeHeader is a common part of `mail.Header` and `textproto.MIMEHeader`.
+type mimeHeader interface {
+	Get(key string) string
+}
+
+// readMimePart adds attachments from the MIME part (recursively for multipart ones) to `entries`.
+func readMimePart(name string, header mimeHeader, body io.Reader, entries *[]sourceFile) error {
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		mediaType = "text/plain" // Default by RFC 2045.
+	}
+	if strings.HasPrefix(mediaType, "multipart/") {
+		partsReader := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := partsReader.NextRawPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("can't read MIME part of '%s': %w", name, err)
+			}
+			if err := readMimePart(name, part.Header, part, entries); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Only parts with file name are attachments.
+	fileName := params["name"]
+	if _, dispositionParams, err := mime.ParseMediaType(header.Get("Content-Disposition")); err == nil &&
+		dispositionParams["filename"] != "" {
+		fileName = dispositionParams["filename"]
+	}
+	if fileName == "" {
+		return nil
+	}
+	if decoded, err := new(mime.WordDecoder).DecodeHeader(fileName); err == nil {
+		fileName = decoded
+	}
+	switch strings.ToLower(strings.TrimSpace(header.Get("Content-Transfer-Encoding"))) {
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body) // Skips new lines itself.
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	}
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return fmt.Errorf("can't decode '%s' attachment of '%s': %w", fileName, name, err)
+	}
+	*entries = append(*entries, sourceFile{Name: name + "/" + path.Base(fileName), Data: content, IsEntry: true})
+	return nil
+}
+
+// readMboxEntries returns attachments of all emails in the mailbox (mboxo/mboxrd format).
+func readMboxEntries(name string, data []byte) ([]sourceFile, error) {
+	entries := []sourceFile{}
+	messages := []*bytes.Buffer{}
+	for _, line := range bytes.SplitAfter(data, []byte("\n")) {
+		if bytes.HasPrefix(line, []byte("From ")) {
+			messages = append(messages, &bytes.Buffer{})
+			continue
+		}
+		if len(messages) == 0 {
+			continue // Garbage before the first message.
+		}
+		// Unescape ">From " lines of mboxrd format.
+		if trimmed := bytes.TrimLeft(line, ">"); len(trimmed) < len(line) && bytes.HasPrefix(trimmed, []byte("From ")) {
+			line = line[1:]
+		}
+		messages[len(messages)-1].Write(line)
+	}
+	for i, message := range messages {
+		messageEntries, err := readEmailEntries(fmt.Sprintf("%s/%d", name, i+1), message)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, messageEntries...)
+	}
+	return entries, nil
+}
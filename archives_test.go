@@ -0,0 +1,130 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadContainerEntries(t *testing.T) {
+	// Arrange
+	zipData := createZip(t, map[string]string{"Statement.xml": inecoValidStatement, "readme.txt": "Hello"})
+	email := strings.Join([]string{
+		"From: bank@example.com",
+		"Subject: Statement",
+		"MIME-Version: 1.0",
+		`Content-Type: multipart/mixed; boundary="BOUNDARY"`,
+		"",
+		"--BOUNDARY",
+		"Content-Type: text/plain; charset=utf-8",
+		"",
+		"Your statement is attached.",
+		"--BOUNDARY",
+		`Content-Type: application/zip; name="statements.zip"`,
+		"Content-Transfer-Encoding: base64",
+		`Content-Disposition: attachment; filename="statements.zip"`,
+		"",
+		wrapLines(base64.StdEncoding.EncodeToString(zipData), 76),
+		"--BOUNDARY",
+		`Content-Type: text/csv; name="=?UTF-8?B?0LLRi9C/0LjRgdC60LAuY3N2?="`,
+		"Content-Transfer-Encoding: quoted-printable",
+		"",
+		"Date=3B Amount",
+		"--BOUNDARY--",
+		"",
+	}, "\r\n")
+	mbox := "From bank@example.com Mon Sep  4 10:00:00 2023\n" + strings.ReplaceAll(email, "\r\n", "\n") +
+		"\nFrom other@example.com Mon Sep  5 10:00:00 2023\nSubject: No attachments\n\n>From here\n"
+	tests := []struct {
+		name     string
+		data     []byte
+		expected map[string]string
+	}{
+		{
+			name:     "statements.zip",
+			data:     zipData,
+			expected: map[string]string{"statements.zip/Statement.xml": inecoValidStatement, "statements.zip/readme.txt": "Hello"},
+		},
+		{
+			name: "mail.eml",
+			data: []byte(email),
+			expected: map[string]string{
+				"mail.eml/statements.zip/Statement.xml": inecoValidStatement,
+				"mail.eml/statements.zip/readme.txt":    "Hello",
+				"mail.eml/выписка.csv":                  "Date; Amount",
+			},
+		},
+		{
+			name: "inbox.mbox",
+			data: []byte(mbox),
+			expected: map[string]string{
+				"inbox.mbox/1/statements.zip/Statement.xml": inecoValidStatement,
+				"inbox.mbox/1/statements.zip/readme.txt":    "Hello",
+				"inbox.mbox/1/выписка.csv":                  "Date; Amount",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// Act
+			entries, err := readContainerEntries(tt.name, tt.data)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("readContainerEntries() failed: %v", err)
+			}
+			actual := map[string]string{}
+			for _, entry := range entries {
+				if !entry.IsEntry {
+					t.Errorf("Expected '%s' to be marked as entry", entry.Name)
+				}
+				actual[entry.Name] = string(entry.Data)
+			}
+			if !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("readContainerEntries() = %v, want %v", actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestReadContainerEntries_BrokenZip(t *testing.T) {
+	// Act
+	_, err := readContainerEntries("broken.zip", []byte("PK not really"))
+
+	// Assert
+	if err == nil {
+		t.Fatal("Expected error, but got no error")
+	}
+	checkErrorContainsSubstring(t, err, "can't read 'broken.zip' ZIP archive")
+}
+
+func createZip(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for name, content := range files {
+		fileWriter, err := writer.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := fileWriter.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func wrapLines(text string, width int) string {
+	lines := []string{}
+	for len(text) > width {
+		lines = append(lines, text[:width])
+		text = text[width:]
+	}
+	return strings.Join(append(lines, text), "\r\n")
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// VerifyStatements checks that for each statement "opening + incomes - expenses = closing".
+// Returns list of warnings about mismatches.
+func VerifyStatements(statements []AccountStatement) []Diagnostic {
+	warnings := []Diagnostic{}
+	for _, s := range statements {
+		expected := s.OpeningBalance.int + s.TotalIncome.int - s.TotalExpense.int
+		if expected != s.ClosingBalance.int {
+			warnings = append(warnings, Diagnostic{Severity: SeverityWarning, File: s.Source, Message: fmt.Sprintf(
+				"Balance mismatch for account %s: opening %s + incomes %s - expenses %s = %s, but closing balance is %s.",
+				s.Account,
+				strings.TrimSpace(s.OpeningBalance.String()),
+				strings.TrimSpace(s.TotalIncome.String()),
+				strings.TrimSpace(s.TotalExpense.String()),
+				strings.TrimSpace(MoneyWith2DecimalPlaces{expected}.String()),
+				strings.TrimSpace(s.ClosingBalance.String()),
+			)})
+		}
+	}
+	return warnings
+}
+
+// latestStatementBefore returns statement of the account with the latest start not after `date`.
+func latestStatementBefore(statements []AccountStatement, account string, date time.Time) *AccountStatement {
+	var result *AccountStatement
+	for i, s := range statements {
+		if s.Account != account || s.Start.After(date) {
+			continue
+		}
+		if result == nil || s.Start.After(result.Start) {
+			result = &statements[i]
+		}
+	}
+	return result
+}
+
+// ComputeRunningBalances sets `Transaction.Balance` for all transactions of accounts with known
+// statements. Balance is counted from the opening balance of the latest statement started before
+// the transaction. Transactions from the same day keep order from the statement files.
+func ComputeRunningBalances(transactions []Transaction, statements []AccountStatement) {
+	indexes := make([]int, 0, len(transactions))
+	for i, t := range transactions {
+		if t.Account != "" {
+			indexes = append(indexes, i)
+		}
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return transactions[indexes[i]].Date.Before(transactions[indexes[j]].Date)
+	})
+
+	// Map of account to the current statement and balance.
+	anchors := map[string]*AccountStatement{}
+	balances := map[string]int{}
+	for _, i := range indexes {
+		t := &transactions[i]
+		statement := latestStatementBefore(statements, t.Account, t.Date)
+		if statement == nil {
+			continue
+		}
+		if anchors[t.Account] != statement {
+			anchors[t.Account] = statement
+			balances[t.Account] = statement.OpeningBalance.int
+		}
+		if t.IsExpense {
+			balances[t.Account] -= t.Amount.int
+		} else {
+			balances[t.Account] += t.Amount.int
+		}
+		t.Balance = &MoneyWith2DecimalPlaces{balances[t.Account]}
+	}
+}
+
+// AssignIntervalBalances sets `IntervalStatistic.Balances` for each interval as opening balance of
+// the latest statement started before the end of interval plus all account transactions since.
+func AssignIntervalBalances(
+	statistics []*IntervalStatistic,
+	transactions []Transaction,
+	statements []AccountStatement,
+) {
+	accounts := map[string]bool{}
+	for _, s := range statements {
+		accounts[s.Account] = true
+	}
+	for _, stat := range statistics {
+		balances := map[string]MoneyWith2DecimalPlaces{}
+		for account := range accounts {
+			statement := latestStatementBefore(statements, account, stat.End)
+			if statement == nil {
+				continue
+			}
+			balance := statement.OpeningBalance.int
+			for _, t := range transactions {
+				if t.Account != account || t.Date.Before(statement.Start) || t.Date.After(stat.End) {
+					continue
+				}
+				if t.IsExpense {
+					balance -= t.Amount.int
+				} else {
+					balance += t.Amount.int
+				}
+			}
+			balances[account] = MoneyWith2DecimalPlaces{balance}
+		}
+		stat.Balances = balances
+	}
+}
+
+// BalancesToString converts map of account balances to human readable string.
+func BalancesToString(balances map[string]MoneyWith2DecimalPlaces) string {
+	if len(balances) == 0 {
+		return ""
+	}
+	accounts := make([]string, 0, len(balances))
+	total := 0
+	for account, balance := range balances {
+		accounts = append(accounts, account)
+		total += balance.int
+	}
+	sort.Strings(accounts)
+	lines := make([]string, len(accounts))
+	for i, account := range accounts {
+		lines[i] = fmt.Sprintf("\n    %-35s: %s", account, balances[account])
+	}
+	return fmt.Sprintf("\n  Balances at the end (sum=%s):%s",
+		MoneyWith2DecimalPlaces{total}, strings.Join(lines, ""))
+}
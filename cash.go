@@ -0,0 +1,96 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// cashAllocationMaxDays is a number of days after cash withdrawal when cash may be spent.
+const cashAllocationMaxDays = 62
+
+// cashWithdrawal is a withdrawal of cash with not spent yet amount.
+type cashWithdrawal struct {
+	transaction *Transaction
+	remaining   int
+}
+
+// AllocateCashSpending links manual expenses paid by cash (`Transaction.IsCash`) to the latest
+// previous cash withdrawal, i.e. expense with details containing one of `withdrawalSubstrings`,
+// which still has enough not spent money and is made not earlier than `cashAllocationMaxDays` ago.
+// Linked amount is added to `Transaction.Allocated` of the withdrawal, so the withdrawal group
+// gets only not explained part of cash. Returns warnings about cash expenses without withdrawal.
+func AllocateCashSpending(transactions []Transaction, withdrawalSubstrings []string) []Diagnostic {
+	indexes := make([]int, len(transactions))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return transactions[indexes[i]].Date.Before(transactions[indexes[j]].Date)
+	})
+	withdrawals := []*cashWithdrawal{}
+	diagnostics := []Diagnostic{}
+	for _, i := range indexes {
+		t := &transactions[i]
+		if !t.IsExpense {
+			continue
+		}
+		if !t.IsCash {
+			if isCashWithdrawal(*t, withdrawalSubstrings) {
+				withdrawals = append(withdrawals, &cashWithdrawal{transaction: t, remaining: t.Amount.int})
+			}
+			continue
+		}
+		withdrawal := findCashWithdrawal(withdrawals, *t, t.Date.AddDate(0, 0, -cashAllocationMaxDays))
+		if withdrawal == nil {
+			diagnostics = append(diagnostics, Diagnostic{
+				Severity: SeverityWarning,
+				File:     sourceFileName(t.Source),
+				Raw:      t.String(),
+				Message:  "Can't find cash withdrawal with enough money to allocate cash expense from.",
+			})
+			continue
+		}
+		withdrawal.remaining -= t.Amount.int
+		withdrawal.transaction.Allocated.int += t.Amount.int
+	}
+	return diagnostics
+}
+
+// isCashWithdrawal checks that transaction is a cash withdrawal.
+func isCashWithdrawal(t Transaction, withdrawalSubstrings []string) bool {
+	for _, substring := range withdrawalSubstrings {
+		if strings.Contains(t.Details, substring) {
+			return true
+		}
+	}
+	return false
+}
+
+// findCashWithdrawal returns the latest withdrawal made after `since` with enough not spent money
+// in the same currency or nil if there are no such.
+func findCashWithdrawal(withdrawals []*cashWithdrawal, expense Transaction, since time.Time) *cashWithdrawal {
+	for i := len(withdrawals) - 1; i >= 0; i-- {
+		withdrawal := withdrawals[i]
+		if withdrawal.transaction.Date.Before(since) {
+			break
+		}
+		if withdrawal.remaining < expense.Amount.int {
+			continue
+		}
+		currency := withdrawal.transaction.Currency
+		if currency != "" && expense.Currency != "" && !strings.EqualFold(currency, expense.Currency) {
+			continue
+		}
+		return withdrawal
+	}
+	return nil
+}
+
+// sourceFileName returns name of the file from `Transaction.Source` like "'file' by Parser".
+func sourceFileName(source string) string {
+	if name, _, ok := strings.Cut(strings.TrimPrefix(source, "'"), "' by "); ok {
+		return name
+	}
+	return source
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAllocateCashSpending(t *testing.T) {
+	// Arrange
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	newT := func(days int, amount int, details string, isCash bool) Transaction {
+		return Transaction{
+			IsExpense: true,
+			Date:      day.AddDate(0, 0, days),
+			Details:   details,
+			Amount:    MoneyWith2DecimalPlaces{amount},
+			Currency:  "AMD",
+			IsCash:    isCash,
+			Source:    "'cash.yaml' by ManualFileParser",
+		}
+	}
+	transactions := []Transaction{
+		newT(0, 3000000, "INECO ATM KOMITAS", false),
+		newT(1, 450000, "Market", true),
+		newT(2, 300000, "Pharmacy", true),
+		newT(3, 1000000, "INECO ATM KOMITAS", false),
+		newT(4, 1500000, "Furniture", true), // Is bigger than the last withdrawal, goes to the first one.
+		newT(5, 5000000, "Car", true),       // Is bigger than any withdrawal.
+	}
+
+	// Act
+	diagnostics := AllocateCashSpending(transactions, []string{"ATM"})
+
+	// Assert
+	if transactions[0].Allocated.int != 2250000 || transactions[3].Allocated.int != 0 {
+		t.Errorf("Allocated %d and %d, want 2250000 and 0", transactions[0].Allocated.int, transactions[3].Allocated.int)
+	}
+	if len(diagnostics) != 1 || diagnostics[0].File != "cash.yaml" || diagnostics[0].Severity != SeverityWarning {
+		t.Errorf("Expected one warning about 'Car' in 'cash.yaml', got %v", diagnostics)
+	}
+}
+
+func TestHandleTransaction_ManualCash(t *testing.T) {
+	// Arrange
+	withdrawal := Transaction{IsExpense: true, Date: now, Details: "INECO ATM", Amount: MoneyWith2DecimalPlaces{2000000},
+		Allocated: MoneyWith2DecimalPlaces{450000}}
+	market := Transaction{IsExpense: true, Date: now, Details: "Market", Amount: MoneyWith2DecimalPlaces{450000},
+		Group: "Groceries", IsCash: true}
+	builder, err := NewStatisticBuilderByDetailsSubstrings(&Config{
+		GroupNamesToSubstrings:      map[string][]string{"Cash": {"ATM"}, "Fun": {"Market"}},
+		GroupAllUnknownTransactions: true,
+	})
+	if err != nil {
+		t.Fatalf("NewStatisticBuilderByDetailsSubstrings() failed: %#v", err)
+	}
+	handler := builder(now, nowPlusMonth)
+
+	// Act
+	for _, trans := range []Transaction{withdrawal, market} {
+		if err := handler.HandleTransaction(trans); err != nil {
+			t.Errorf("HandleTransaction() failed on %v with %#v", trans, err)
+		}
+	}
+
+	// Assert
+	expected := newIntervalStatistic()
+	expected.Expense = map[string]*Group{
+		"Cash":      {Name: "Cash", Total: MoneyWith2DecimalPlaces{1550000}, Transactions: []Transaction{withdrawal}},
+		"Groceries": groupFromITs("Groceries", []Transaction{market}),
+	}
+	assertIntervalStatisticEqual(expected, handler.GetIntervalStatistic(), t)
+}
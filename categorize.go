@@ -0,0 +1,287 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	categorizeSuggestionsLimit = 3
+	categorizeRegexpPrefix     = "re:"
+	categorizeQuitCommand      = "q"
+)
+
+// unknownDetails aggregates "unknown" transactions with the same `Transaction.Details`.
+type unknownDetails struct {
+	Details   string
+	IsExpense bool
+	Count     int
+	Total     MoneyWith2DecimalPlaces
+	First     time.Time
+	Last      time.Time
+}
+
+// groupSuggestion is a known group similar to some transaction.
+type groupSuggestion struct {
+	GroupName string
+	Score     float64
+}
+
+// collectUnknownDetails returns "unknown" transactions aggregated by details, biggest totals first.
+func collectUnknownDetails(statistics []*IntervalStatistic) []*unknownDetails {
+	byDetails := map[string]*unknownDetails{}
+	for _, s := range statistics {
+		for _, mapOfGroups := range []map[string]*Group{s.Income, s.Expense} {
+			group, exists := mapOfGroups[UnknownGroupName]
+			if !exists {
+				continue
+			}
+			for _, t := range group.Transactions {
+				item, exists := byDetails[t.Details]
+				if !exists {
+					item = &unknownDetails{Details: t.Details, IsExpense: t.IsExpense, First: t.Date, Last: t.Date}
+					byDetails[t.Details] = item
+				}
+				item.Count++
+				item.Total.int += t.Amount.int
+				if t.Date.Before(item.First) {
+					item.First = t.Date
+				}
+				if t.Date.After(item.Last) {
+					item.Last = t.Date
+				}
+			}
+		}
+	}
+	result := make([]*unknownDetails, 0, len(byDetails))
+	for _, item := range byDetails {
+		result = append(result, item)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Total.int != result[j].Total.int {
+			return result[i].Total.int > result[j].Total.int
+		}
+		return result[i].Details < result[j].Details
+	})
+	return result
+}
+
+// collectGroupsTokens returns map of group name to counts of tokens from rules of the group
+// and details of transactions already assigned to the group.
+func collectGroupsTokens(statistics []*IntervalStatistic, config *Config) map[string]map[string]int {
+	result := map[string]map[string]int{}
+	addTokens := func(groupName, text string) {
+		tokens, exists := result[groupName]
+		if !exists {
+			tokens = map[string]int{}
+			result[groupName] = tokens
+		}
+		for _, token := range detailsTokens(text) {
+			tokens[token]++
+		}
+	}
+	for groupName, substrings := range config.GroupNamesToSubstrings {
+		for _, substring := range substrings {
+			addTokens(groupName, substring)
+		}
+	}
+	for _, s := range statistics {
+		for _, mapOfGroups := range []map[string]*Group{s.Income, s.Expense} {
+			for groupName, group := range mapOfGroups {
+				if groupName == UnknownGroupName {
+					continue
+				}
+				for _, t := range group.Transactions {
+					addTokens(groupName, t.Details)
+				}
+			}
+		}
+	}
+	return result
+}
+
+// suggestGroups returns up to `limit` groups which tokens overlap with tokens of `details` most.
+func suggestGroups(details string, groupsTokens map[string]map[string]int, limit int) []groupSuggestion {
+	tokens := detailsTokens(details)
+	if len(tokens) == 0 {
+		return nil
+	}
+	suggestions := []groupSuggestion{}
+	for groupName, groupTokens := range groupsTokens {
+		matched := 0
+		for _, token := range tokens {
+			if groupTokens[token] > 0 {
+				matched++
+			}
+		}
+		if matched > 0 {
+			suggestions = append(suggestions, groupSuggestion{groupName, float64(matched) / float64(len(tokens))})
+		}
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Score != suggestions[j].Score {
+			return suggestions[i].Score > suggestions[j].Score
+		}
+		return suggestions[i].GroupName < suggestions[j].GroupName
+	})
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions
+}
+
+// ruleMatches checks that `ConfigRule` matches details.
+func ruleMatches(rule ConfigRule, details string) bool {
+	if rule.IsRegexp {
+		matched, err := regexp.MatchString(rule.Pattern, details)
+		return err == nil && matched
+	}
+	return strings.Contains(details, rule.Pattern)
+}
+
+// askRules walks through "unknown" transactions and asks user to choose group and pattern for them.
+// Returns rules chosen by user.
+func askRules(
+	unknowns []*unknownDetails,
+	groupsTokens map[string]map[string]int,
+	in io.Reader,
+	out io.Writer,
+) ([]ConfigRule, error) {
+	scanner := bufio.NewScanner(in)
+	readLine := func(prompt string) (string, bool) {
+		fmt.Fprint(out, prompt)
+		if !scanner.Scan() {
+			return "", false
+		}
+		return strings.TrimSpace(scanner.Text()), true
+	}
+
+	rules := []ConfigRule{}
+	for i, item := range unknowns {
+
+		// Skip transactions already handled by new rules.
+		isHandled := false
+		for _, rule := range rules {
+			if ruleMatches(rule, item.Details) {
+				isHandled = true
+				break
+			}
+		}
+		if isHandled {
+			continue
+		}
+
+		// Show transaction details and suggestions.
+		direction := "Income"
+		if item.IsExpense {
+			direction = "Expense"
+		}
+		fmt.Fprintf(out, "\n[%d/%d] %s, %d transaction(s) with sum %s in %s..%s:\n  %s\n",
+			i+1, len(unknowns), direction, item.Count, strings.TrimSpace(item.Total.String()),
+			item.First.Format(OutputDateFormat), item.Last.Format(OutputDateFormat), item.Details)
+		suggestions := suggestGroups(item.Details, groupsTokens, categorizeSuggestionsLimit)
+		for j, suggestion := range suggestions {
+			fmt.Fprintf(out, "  %d) %s (%.0f%% similar)\n", j+1, suggestion.GroupName, suggestion.Score*100)
+		}
+
+		// Ask group.
+		answer, ok := readLine(fmt.Sprintf(
+			"Group number or new group name (empty to skip, %q to save and quit): ", categorizeQuitCommand))
+		if !ok || answer == categorizeQuitCommand {
+			break
+		}
+		if answer == "" {
+			continue
+		}
+		groupName := answer
+		if number, err := strconv.Atoi(answer); err == nil && number >= 1 && number <= len(suggestions) {
+			groupName = suggestions[number-1].GroupName
+		}
+
+		// Ask pattern until it matches details.
+		for {
+			answer, ok = readLine(fmt.Sprintf(
+				"Substring to match (empty for whole details, \"%s\" prefix for regular expression): ",
+				categorizeRegexpPrefix))
+			if !ok {
+				return rules, scanner.Err()
+			}
+			rule := ConfigRule{GroupName: groupName, Pattern: answer}
+			if answer == "" {
+				rule.Pattern = item.Details
+			} else if strings.HasPrefix(answer, categorizeRegexpPrefix) {
+				rule.Pattern = strings.TrimPrefix(answer, categorizeRegexpPrefix)
+				rule.IsRegexp = true
+				if _, err := regexp.Compile(rule.Pattern); err != nil {
+					fmt.Fprintf(out, "Wrong regular expression: %v\n", err)
+					continue
+				}
+			}
+			if !ruleMatches(rule, item.Details) {
+				fmt.Fprintf(out, "'%s' doesn't match details, try again.\n", rule.Pattern)
+				continue
+			}
+			rules = append(rules, rule)
+			break
+		}
+	}
+	return rules, scanner.Err()
+}
+
+// buildStatisticsWithCommonUnknownGroup builds monthly statistics from configuration but puts all
+// transactions not matched by rules into the single `UnknownGroupName` group.
+func buildStatisticsWithCommonUnknownGroup(
+	config *Config,
+	transactions []Transaction,
+	timeZone *time.Location,
+) ([]*IntervalStatistic, error) {
+	commonUnknownConfig := *config
+	commonUnknownConfig.GroupAllUnknownTransactions = true
+	factory, err := NewStatisticBuilderByDetailsSubstrings(&commonUnknownConfig)
+	if err != nil {
+		return nil, err
+	}
+	return BuildMonthlyStatistic(transactions, factory, config.MonthStartDayNumber, timeZone)
+}
+
+// runCategorization asks user about groups for "unknown" transactions and saves chosen rules
+// into the configuration file.
+func runCategorization(
+	configPath string,
+	config *Config,
+	transactions []Transaction,
+	timeZone *time.Location,
+	in io.Reader,
+	out io.Writer,
+) error {
+
+	statistics, err := buildStatisticsWithCommonUnknownGroup(config, transactions, timeZone)
+	if err != nil {
+		return err
+	}
+	unknowns := collectUnknownDetails(statistics)
+	if len(unknowns) == 0 {
+		fmt.Fprintln(out, "There are no 'unknown' transactions.")
+		return nil
+	}
+
+	rules, err := askRules(unknowns, collectGroupsTokens(statistics, config), in, out)
+	if err != nil {
+		return err
+	}
+	if len(rules) == 0 {
+		fmt.Fprintln(out, "\nNo new rules to save.")
+		return nil
+	}
+	if err = addRulesToConfigFile(configPath, rules); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "\nSaved %d new rule(s) into '%s'.\n", len(rules), configPath)
+	return nil
+}
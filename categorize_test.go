@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAskRules(t *testing.T) {
+	// Arrange
+	unknowns := []*unknownDetails{
+		{Details: "SAS SUPERMARKET ARABKIR", IsExpense: true, Count: 2, Total: MoneyWith2DecimalPlaces{2000}},
+		{Details: "SAS SUPERMARKET CENTER", IsExpense: true, Count: 1, Total: MoneyWith2DecimalPlaces{1000}},
+		{Details: "YANDEX.GO 12345", IsExpense: true, Count: 1, Total: MoneyWith2DecimalPlaces{500}},
+		{Details: "SOMETHING ELSE", IsExpense: true, Count: 1, Total: MoneyWith2DecimalPlaces{100}},
+	}
+	groupsTokens := map[string]map[string]int{
+		"Groceries": {"SUPERMARKET": 1, "MARKET": 3},
+		"Taxi":      {"YANDEX": 2},
+	}
+	// 1st: choose suggestion, wrong substring, then right one. 2nd is skipped as matched by 1st rule.
+	// 3rd: new group with regular expression. 4th: quit.
+	input := strings.Join([]string{"1", "WRONG", "SAS SUPERMARKET", "Transport", "re:^YANDEX\\.GO", "q"}, "\n")
+	var out bytes.Buffer
+
+	// Act
+	rules, err := askRules(unknowns, groupsTokens, strings.NewReader(input), &out)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("askRules() failed: %v", err)
+	}
+	expected := []ConfigRule{
+		{GroupName: "Groceries", Pattern: "SAS SUPERMARKET"},
+		{GroupName: "Transport", Pattern: "^YANDEX\\.GO", IsRegexp: true},
+	}
+	if !reflect.DeepEqual(rules, expected) {
+		t.Errorf("askRules() = %+v, want %+v", rules, expected)
+	}
+	if !strings.Contains(out.String(), "'WRONG' doesn't match details") {
+		t.Errorf("Expected message about wrong substring in output:\n%s", out.String())
+	}
+}
+
+func TestAddRulesToConfigFile(t *testing.T) {
+	// Arrange
+	tempFile := createTempFileWithContent(
+		`inecobankStatementFilesGlob: "*.xml"
+ameriaCsvFilesGlob: "*.csv"
+myAmeriaHistoryFilesGlob: "*.xls"
+detailedOutput: false
+groupAllUnknownTransactions: true
+# Groups comment.
+groupNamesToSubstrings:
+  g1:
+    - Sub1 # Sub1 comment.
+  g2:
+    - Sub3
+`,
+	)
+	defer os.Remove(tempFile.Name())
+
+	// Act
+	err := addRulesToConfigFile(tempFile.Name(), []ConfigRule{
+		{GroupName: "g1", Pattern: "Sub2"},
+		{GroupName: "g3", Pattern: "Sub4"},
+		{GroupName: "g3", Pattern: "^Re", IsRegexp: true},
+	})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("addRulesToConfigFile() failed: %v", err)
+	}
+	content, _ := os.ReadFile(tempFile.Name())
+	for _, comment := range []string{"# Groups comment.", "# Sub1 comment."} {
+		if !strings.Contains(string(content), comment) {
+			t.Errorf("Comment '%s' is lost:\n%s", comment, content)
+		}
+	}
+	cfg, err := readConfig(tempFile.Name())
+	if err != nil {
+		t.Fatalf("readConfig() failed: %v\n%s", err, content)
+	}
+	expectedSubstrings := map[string][]string{
+		"g1": {"Sub1", "Sub2"},
+		"g2": {"Sub3"},
+		"g3": {"Sub4"},
+	}
+	if !reflect.DeepEqual(cfg.GroupNamesToSubstrings, expectedSubstrings) {
+		t.Errorf("Expected GroupNamesToSubstrings %v, got %v", expectedSubstrings, cfg.GroupNamesToSubstrings)
+	}
+	expectedRegexps := map[string][]string{"g3": {"^Re"}}
+	if !reflect.DeepEqual(cfg.GroupNamesToRegexps, expectedRegexps) {
+		t.Errorf("Expected GroupNamesToRegexps %v, got %v", expectedRegexps, cfg.GroupNamesToRegexps)
+	}
+}
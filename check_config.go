@@ -0,0 +1,349 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// SeverityError marks issues making part of configuration useless.
+	SeverityError = "error"
+	// SeverityWarning marks issues which are probably mistakes.
+	SeverityWarning = "warning"
+)
+
+// largeIgnoredShare is a share of sum of all transactions which may be ignored by one ignore
+// rule without warning.
+const largeIgnoredShare = 0.1
+
+// mixedGroupShare is a share of the smaller direction (income or expense) in the group
+// starting from which the group is treated as mixed.
+const mixedGroupShare = 0.2
+
+// ConfigIssue is a problem found in configuration by `CheckConfig`.
+type ConfigIssue struct {
+	Severity string `json:"severity"`
+	Check    string `json:"check"`
+	Message  string `json:"message"`
+}
+
+func (i ConfigIssue) String() string {
+	return fmt.Sprintf("%s [%s] %s", strings.ToUpper(i.Severity), i.Check, i.Message)
+}
+
+// configRule is a rule from configuration with its place.
+type configRule struct {
+	section   string
+	pattern   string
+	groupName string
+	isIgnore  bool
+	isRegexp  bool
+	// scope is index of `accountRules` item or -1 for common rules.
+	scope int
+}
+
+func (r configRule) key() string {
+	return r.section + "\x00" + r.pattern
+}
+
+func (r configRule) String() string {
+	if r.isIgnore {
+		return fmt.Sprintf("%s %q", r.section, r.pattern)
+	}
+	return fmt.Sprintf("%s %q of '%s' group", r.section, r.pattern, r.groupName)
+}
+
+// collectConfigRules returns all rules of the extractor in order of `matchRules` sections.
+func (s groupExtractorByDetailsSubstrings) collectConfigRules() []configRule {
+	rules := []configRule{}
+	for _, substring := range s.ignoreSubstrings {
+		rules = append(rules, configRule{section: "ignoreSubstrings", pattern: substring, isIgnore: true, scope: -1})
+	}
+	for i, scoped := range s.accountRules {
+		for _, substring := range scoped.ignoreSubstrings {
+			rules = append(rules, configRule{
+				section:  "accountRules.ignoreSubstrings",
+				pattern:  substring,
+				isIgnore: true,
+				scope:    i,
+			})
+		}
+	}
+	for _, rule := range s.ignoreRules {
+		// Conditions of the rule can't be compared as substrings, check them like regular expressions.
+		rules = append(rules, configRule{
+			section:  "ignoreRules",
+			pattern:  rule.label,
+			isIgnore: true,
+			isRegexp: true,
+			scope:    -1,
+		})
+	}
+	for i, scoped := range s.accountRules {
+		for _, substring := range sortedSubstrings(scoped.substringsToGroupName) {
+			rules = append(rules, configRule{
+				section:   "accountRules.groupNamesToSubstrings",
+				pattern:   substring,
+				groupName: scoped.substringsToGroupName[substring],
+				scope:     i,
+			})
+		}
+	}
+	for _, substring := range sortedSubstrings(s.substringsToGroupName) {
+		rules = append(rules, configRule{
+			section:   "groupNamesToSubstrings",
+			pattern:   substring,
+			groupName: s.substringsToGroupName[substring],
+			scope:     -1,
+		})
+	}
+	for _, r := range s.regexpsToGroupName {
+		rules = append(rules, configRule{
+			section:   "groupNamesToRegexps",
+			pattern:   r.regexp.String(),
+			groupName: r.groupName,
+			isRegexp:  true,
+			scope:     -1,
+		})
+	}
+	return rules
+}
+
+// sortedSubstrings returns keys of the substrings to group name map in stable order.
+func sortedSubstrings(substringsToGroupName map[string]string) []string {
+	substrings := make([]string, 0, len(substringsToGroupName))
+	for substring := range substringsToGroupName {
+		substrings = append(substrings, substring)
+	}
+	sort.Strings(substrings)
+	return substrings
+}
+
+// CheckConfig checks configuration for problems which validation of the file can't find.
+// Checks globs without files, substrings shadowed by other substrings and, if transactions
+// are provided, rules which don't match any transaction, ignore rules hiding large amounts and
+// groups with both incomes and expenses.
+func CheckConfig(
+	config *Config,
+	groupExtractorFactory StatisticBuilderFactory,
+	transactions []Transaction,
+) ([]ConfigIssue, error) {
+	extractor, ok := groupExtractorFactory(time.Time{}, time.Time{}).(groupExtractorByDetailsSubstrings)
+	if !ok {
+		return nil, fmt.Errorf("statistic builder doesn't support configuration check")
+	}
+	issues := checkGlobs(config)
+	rules := extractor.collectConfigRules()
+	issues = append(issues, checkShadowedSubstrings(rules)...)
+	if len(transactions) > 0 {
+		issues = append(issues, checkRulesUsage(extractor, rules, transactions)...)
+		issues = append(issues, checkMixedGroups(extractor, transactions)...)
+	}
+	return issues, nil
+}
+
+// checkGlobs reports globs of sources which don't match any file.
+func checkGlobs(config *Config) []ConfigIssue {
+	issues := []ConfigIssue{}
+	for i, source := range config.Sources {
+		for _, glob := range source.Globs {
+			files, err := getFilesByGlob(glob)
+			if err != nil {
+				issues = append(issues, ConfigIssue{SeverityError, "glob", fmt.Sprintf(
+					"source #%d (%s) has wrong glob '%s': %v", i+1, source.Type, glob, err)})
+			} else if len(files) == 0 {
+				issues = append(issues, ConfigIssue{SeverityWarning, "glob", fmt.Sprintf(
+					"source #%d (%s) glob '%s' doesn't match any file", i+1, source.Type, glob)})
+			}
+		}
+	}
+	return issues
+}
+
+// checkShadowedSubstrings reports substrings which contain other substrings applied to the same
+// transactions. Such rules are either useless or depend on priority of rules.
+func checkShadowedSubstrings(rules []configRule) []ConfigIssue {
+	issues := []ConfigIssue{}
+	for _, long := range rules {
+		if long.isRegexp {
+			continue
+		}
+		for _, short := range rules {
+			if short.isRegexp || short.key() == long.key() || !strings.Contains(long.pattern, short.pattern) {
+				continue
+			}
+			if short.scope >= 0 && long.scope >= 0 && short.scope != long.scope {
+				continue // Different accounts.
+			}
+			isSame := long.pattern == short.pattern
+			if isSame && long.isIgnore == short.isIgnore && !(long.scope >= 0 && short.scope < 0) {
+				continue // The same pair is reported with the account specific rule as `long`.
+			}
+			switch {
+			case short.isIgnore && !long.isIgnore && short.scope >= 0 && long.scope < 0:
+				issues = append(issues, ConfigIssue{SeverityWarning, "shadowing", fmt.Sprintf(
+					"%s never applies to accounts of account rules #%d because %s ignores such transactions",
+					long, short.scope+1, short)})
+			case short.isIgnore && !long.isIgnore:
+				issues = append(issues, ConfigIssue{SeverityError, "shadowing", fmt.Sprintf(
+					"%s never applies because all such transactions are ignored by %s", long, short)})
+			case short.isIgnore && long.isIgnore:
+				issues = append(issues, ConfigIssue{SeverityWarning, "shadowing", fmt.Sprintf(
+					"%s is redundant because %s ignores the same transactions", long, short)})
+			case !short.isIgnore && !long.isIgnore && isSame && short.groupName != long.groupName:
+				issues = append(issues, ConfigIssue{SeverityWarning, "shadowing", fmt.Sprintf(
+					"%s overrides %s for accounts of account rules #%d", long, short, long.scope+1)})
+			case !short.isIgnore && !long.isIgnore && short.groupName == long.groupName:
+				issues = append(issues, ConfigIssue{SeverityWarning, "shadowing", fmt.Sprintf(
+					"%s is redundant because %s matches the same transactions", long, short)})
+			case !short.isIgnore && !long.isIgnore:
+				issues = append(issues, ConfigIssue{SeverityWarning, "shadowing", fmt.Sprintf(
+					"%s contains %s, transactions matching both go to '%s' group",
+					long, short, winnerGroupName(long, short))})
+			}
+		}
+	}
+	return issues
+}
+
+// winnerGroupName returns group name of the rule which wins in `matchRules`.
+func winnerGroupName(long, short configRule) string {
+	if short.scope >= 0 && long.scope < 0 {
+		return short.groupName // Account specific rules have priority.
+	}
+	return long.groupName
+}
+
+// checkRulesUsage reports rules which don't match any transaction or never win,
+// and ignore rules hiding large amounts.
+func checkRulesUsage(
+	extractor groupExtractorByDetailsSubstrings,
+	rules []configRule,
+	transactions []Transaction,
+) []ConfigIssue {
+	matched := map[string]int{}
+	applied := map[string]int{}
+	ignoredSums := map[string]int{}
+	ignoredCounts := map[string]int{}
+	totalIncome, totalExpense := 0, 0
+	for _, t := range transactions {
+		if t.IsExpense {
+			totalExpense += t.Amount.int
+		} else {
+			totalIncome += t.Amount.int
+		}
+		matches := extractor.matchRules(t)
+		for i, match := range matches {
+			rule := configRule{section: match.Section, pattern: match.Pattern}
+			matched[rule.key()]++
+			// Rule is useful if it leads to the same result as the winning one.
+			if match.IsIgnore == matches[0].IsIgnore && match.GroupName == matches[0].GroupName {
+				applied[rule.key()]++
+			}
+			if i == 0 && match.IsIgnore {
+				ignoredSums[rule.key()] += t.Amount.int
+				ignoredCounts[rule.key()]++
+			}
+		}
+	}
+
+	issues := []ConfigIssue{}
+	for _, rule := range rules {
+		switch {
+		case matched[rule.key()] == 0:
+			issues = append(issues, ConfigIssue{SeverityWarning, "unused", fmt.Sprintf(
+				"%s doesn't match any of %d transactions", rule, len(transactions))})
+		case applied[rule.key()] == 0:
+			issues = append(issues, ConfigIssue{SeverityWarning, "unused", fmt.Sprintf(
+				"%s matches %d transactions but other rules always win", rule, matched[rule.key()])})
+		}
+	}
+
+	// Compare ignored amounts with all amounts to don't depend on currency.
+	total := totalIncome + totalExpense
+	for _, rule := range rules {
+		sum := ignoredSums[rule.key()]
+		if !rule.isIgnore || sum == 0 || float64(sum) < largeIgnoredShare*float64(total) {
+			continue
+		}
+		issues = append(issues, ConfigIssue{SeverityWarning, "large-ignored", fmt.Sprintf(
+			"%s hides %d transactions with %s sum, it is %.0f%% of all transactions",
+			rule, ignoredCounts[rule.key()], strings.TrimSpace(MoneyWith2DecimalPlaces{sum}.String()),
+			100*float64(sum)/float64(total))})
+	}
+	return issues
+}
+
+// checkMixedGroups reports groups containing both incomes and expenses with significant amounts.
+func checkMixedGroups(extractor groupExtractorByDetailsSubstrings, transactions []Transaction) []ConfigIssue {
+	incomes := map[string]int{}
+	expenses := map[string]int{}
+	for _, t := range transactions {
+		matches := extractor.matchRules(t)
+		if len(matches) == 0 || matches[0].IsIgnore {
+			continue
+		}
+		if t.IsExpense {
+			expenses[matches[0].GroupName] += t.Amount.int
+		} else {
+			incomes[matches[0].GroupName] += t.Amount.int
+		}
+	}
+	groupNames := []string{}
+	for groupName := range incomes {
+		if _, exists := expenses[groupName]; exists {
+			groupNames = append(groupNames, groupName)
+		}
+	}
+	sort.Strings(groupNames)
+	issues := []ConfigIssue{}
+	for _, groupName := range groupNames {
+		income, expense := incomes[groupName], expenses[groupName]
+		smaller := income
+		if expense < income {
+			smaller = expense
+		}
+		if float64(smaller) < mixedGroupShare*float64(income+expense) {
+			continue // Probably refunds or fees.
+		}
+		issues = append(issues, ConfigIssue{SeverityWarning, "mixed-group", fmt.Sprintf(
+			"'%s' group has both incomes (%s) and expenses (%s)", groupName,
+			strings.TrimSpace(MoneyWith2DecimalPlaces{income}.String()),
+			strings.TrimSpace(MoneyWith2DecimalPlaces{expense}.String()))})
+	}
+	return issues
+}
+
+// ConfigIssuesToString returns issues in human readable or JSON format.
+func ConfigIssuesToString(issues []ConfigIssue, isJson bool) (string, error) {
+	if isJson {
+		buf, err := json.MarshalIndent(issues, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(buf) + "\n", nil
+	}
+	if len(issues) == 0 {
+		return "No problems found in configuration.\n", nil
+	}
+	var sb strings.Builder
+	for _, issue := range issues {
+		sb.WriteString(issue.String())
+		sb.WriteString("\n")
+	}
+	fmt.Fprintf(&sb, "Found %d problem(s) in configuration.\n", len(issues))
+	return sb.String(), nil
+}
+
+// HasConfigErrors returns true if there are issues with `SeverityError`.
+func HasConfigErrors(issues []ConfigIssue) bool {
+	for _, issue := range issues {
+		if issue.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
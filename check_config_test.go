@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestCheckConfig(t *testing.T) {
+	// Arrange
+	config := &Config{Sources: []Source{
+		{Type: SourceTypeInecobankXml, Globs: []string{"testdata/ineco/*.xml"}},
+		{Type: SourceTypeAmeriaCsv, Globs: []string{"testdata/not_existing/*.csv"}},
+		{Type: SourceTypeMyAmeriaExcel, Globs: []string{"testdata/ameria/*.xls"}},
+	}}
+	factory, err := NewStatisticBuilderByDetailsSubstrings(&Config{
+		GroupNamesToSubstrings: map[string][]string{
+			"Taxi":      {"YANDEX", "YANDEX GO"},
+			"Food":      {"YANDEX EDA", "SUPERMARKET"},
+			"Transfers": {"MY TRANSFER"},
+			"Unused":    {"NOTHING LIKE THIS"},
+		},
+		GroupAllUnknownTransactions: true,
+		IgnoreSubstrings:            []string{"TRANSFER", "CASH"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	transactions := []Transaction{
+		{IsExpense: true, Details: "YANDEX GO RIDE", Amount: MoneyWith2DecimalPlaces{100000}},
+		{IsExpense: true, Details: "YANDEX EDA", Amount: MoneyWith2DecimalPlaces{100000}},
+		{IsExpense: true, Details: "SUPERMARKET", Amount: MoneyWith2DecimalPlaces{100000}},
+		{IsExpense: false, Details: "SUPERMARKET REFUND", Amount: MoneyWith2DecimalPlaces{90000}},
+		{IsExpense: true, Details: "CASH WITHDRAWAL", Amount: MoneyWith2DecimalPlaces{500000}},
+	}
+
+	// Act
+	issues, err := CheckConfig(config, factory, transactions)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("CheckConfig() failed: %v", err)
+	}
+	messages := []string{}
+	for _, issue := range issues {
+		messages = append(messages, issue.String())
+	}
+	actual := strings.Join(messages, "\n")
+	for _, expected := range []string{
+		"WARNING [glob] source #2 (ameriaCsv) glob 'testdata/not_existing/*.csv' doesn't match any file",
+		"ERROR [shadowing] groupNamesToSubstrings \"MY TRANSFER\" of 'Transfers' group never applies because all such transactions are ignored by ignoreSubstrings \"TRANSFER\"",
+		"WARNING [shadowing] groupNamesToSubstrings \"YANDEX GO\" of 'Taxi' group is redundant because groupNamesToSubstrings \"YANDEX\" of 'Taxi' group matches the same transactions",
+		"WARNING [shadowing] groupNamesToSubstrings \"YANDEX EDA\" of 'Food' group contains groupNamesToSubstrings \"YANDEX\" of 'Taxi' group, transactions matching both go to 'Food' group",
+		"WARNING [unused] groupNamesToSubstrings \"NOTHING LIKE THIS\" of 'Unused' group doesn't match any of 5 transactions",
+		"WARNING [unused] ignoreSubstrings \"TRANSFER\" doesn't match any of 5 transactions",
+		"WARNING [large-ignored] ignoreSubstrings \"CASH\" hides 1 transactions with 50.00 sum, it is 56% of all transactions",
+		"WARNING [mixed-group] 'Food' group has both incomes (9.00) and expenses (20.00)",
+	} {
+		if !strings.Contains(actual, expected) {
+			t.Errorf("Expected '%s' in:\n%s", expected, actual)
+		}
+	}
+	if strings.Contains(actual, "[unused] groupNamesToSubstrings \"YANDEX\"") {
+		t.Errorf("'YANDEX' rule is applied to 'YANDEX GO RIDE' transaction, got:\n%s", actual)
+	}
+	if !HasConfigErrors(issues) {
+		t.Errorf("Expected errors in %+v", issues)
+	}
+	output, err := ConfigIssuesToString(issues, true)
+	if err != nil {
+		t.Fatalf("ConfigIssuesToString() failed: %v", err)
+	}
+	var decoded []ConfigIssue
+	if err := json.Unmarshal([]byte(output), &decoded); err != nil || len(decoded) != len(issues) {
+		t.Errorf("Expected %d issues in JSON, got %v: %s", len(issues), err, output)
+	}
+}
+
+func TestCheckConfig_AccountRulesWithTheSamePatterns(t *testing.T) {
+	// Arrange
+	factory, err := NewStatisticBuilderByDetailsSubstrings(&Config{
+		GroupNamesToSubstrings:      map[string][]string{"Subscriptions": {"CLOUD"}},
+		GroupAllUnknownTransactions: true,
+		IgnoreSubstrings:            []string{"TRANSFER"},
+		AccountRules: []AccountRules{{
+			Accounts:               []string{"business"},
+			IgnoreSubstrings:       []string{"TRANSFER"},
+			GroupNamesToSubstrings: map[string][]string{"Business": {"CLOUD"}},
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	issues, err := CheckConfig(&Config{}, factory, nil)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("CheckConfig() failed: %v", err)
+	}
+	expected := []string{
+		"WARNING [shadowing] accountRules.ignoreSubstrings \"TRANSFER\" is redundant because ignoreSubstrings \"TRANSFER\" ignores the same transactions",
+		"WARNING [shadowing] accountRules.groupNamesToSubstrings \"CLOUD\" of 'Business' group overrides groupNamesToSubstrings \"CLOUD\" of 'Subscriptions' group for accounts of account rules #1",
+	}
+	actual := []string{}
+	for _, issue := range issues {
+		if issue.Check == "shadowing" {
+			actual = append(actual, issue.String())
+		}
+	}
+	if strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(actual, "\n"))
+	}
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"math"
+	"sort"
+)
+
+// PredictedGroupSuffix is added to names of groups with transactions assigned by classifier.
+const PredictedGroupSuffix = " (predicted)"
+
+// classifierReceiverPrefix marks receiver account features to don't mix them with words.
+const classifierReceiverPrefix = "receiver:"
+
+// naiveBayesModel is a multinomial naive Bayes model for one direction (incomes or expenses).
+type naiveBayesModel struct {
+	documents    int
+	groupCounts  map[string]int
+	tokenCounts  map[string]map[string]int
+	groupTokens  map[string]int
+	vocabulary   map[string]bool
+	sortedGroups []string
+}
+
+func newNaiveBayesModel() *naiveBayesModel {
+	return &naiveBayesModel{
+		groupCounts: map[string]int{},
+		tokenCounts: map[string]map[string]int{},
+		groupTokens: map[string]int{},
+		vocabulary:  map[string]bool{},
+	}
+}
+
+// TransactionClassifier predicts group for transactions by groups of already categorized ones.
+// Is trained locally, i.e. doesn't send data anywhere.
+type TransactionClassifier struct {
+	income  *naiveBayesModel
+	expense *naiveBayesModel
+}
+
+// classifierFeatures returns tokens of `Transaction.Details` and `Transaction.ReceiverAccount`.
+func classifierFeatures(t Transaction) []string {
+	features := detailsTokens(t.Details)
+	if t.ReceiverAccount != "" {
+		features = append(features, classifierReceiverPrefix+t.ReceiverAccount)
+	}
+	return features
+}
+
+// TrainClassifier trains classifier on transactions put into groups by rules with the whole amount,
+// i.e. except ones in `UnknownGroupName` group, refunds, portions of split transactions and transactions
+// with manually chosen group. Returns nil if there is nothing to learn from.
+func TrainClassifier(statistics []*IntervalStatistic) *TransactionClassifier {
+	classifier := &TransactionClassifier{income: newNaiveBayesModel(), expense: newNaiveBayesModel()}
+	for _, s := range statistics {
+		for _, group := range s.Income {
+			classifier.income.learn(group)
+		}
+		for _, group := range s.Expense {
+			classifier.expense.learn(group)
+		}
+	}
+	if classifier.income.documents == 0 && classifier.expense.documents == 0 {
+		return nil
+	}
+	for _, model := range []*naiveBayesModel{classifier.income, classifier.expense} {
+		for groupName := range model.groupCounts {
+			model.sortedGroups = append(model.sortedGroups, groupName)
+		}
+		sort.Strings(model.sortedGroups)
+	}
+	return classifier
+}
+
+func (m *naiveBayesModel) learn(group *Group) {
+	if group.Name == UnknownGroupName {
+		return
+	}
+	for _, t := range group.Transactions {
+		if !isGroupedByRules(t) {
+			continue
+		}
+		m.documents++
+		m.groupCounts[group.Name]++
+		counts, exists := m.tokenCounts[group.Name]
+		if !exists {
+			counts = map[string]int{}
+			m.tokenCounts[group.Name] = counts
+		}
+		for _, feature := range classifierFeatures(t) {
+			counts[feature]++
+			m.groupTokens[group.Name]++
+			m.vocabulary[feature] = true
+		}
+	}
+}
+
+// isGroupedByRules checks that the whole transaction is put into its group by rules.
+func isGroupedByRules(t Transaction) bool {
+	return !t.IsRefund && t.SplitOf.int == 0 && t.Group == ""
+}
+
+// Predict returns the most probable group for transaction and its probability.
+// Returns empty group name if transaction doesn't have features known by classifier.
+func (c *TransactionClassifier) Predict(t Transaction) (string, float64) {
+	model := c.income
+	if t.IsExpense {
+		model = c.expense
+	}
+	if model.documents == 0 {
+		return "", 0
+	}
+	features := []string{}
+	for _, feature := range classifierFeatures(t) {
+		if model.vocabulary[feature] {
+			features = append(features, feature)
+		}
+	}
+	if len(features) == 0 {
+		return "", 0
+	}
+
+	// Calculate log-probabilities with Laplace smoothing.
+	logProbabilities := make([]float64, len(model.sortedGroups))
+	maxLogProbability := math.Inf(-1)
+	for i, groupName := range model.sortedGroups {
+		logProbability := math.Log(float64(model.groupCounts[groupName]) / float64(model.documents))
+		denominator := float64(model.groupTokens[groupName] + len(model.vocabulary))
+		for _, feature := range features {
+			logProbability += math.Log(float64(model.tokenCounts[groupName][feature]+1) / denominator)
+		}
+		logProbabilities[i] = logProbability
+		if logProbability > maxLogProbability {
+			maxLogProbability = logProbability
+		}
+	}
+
+	// Normalize into probabilities (softmax) and choose the best one.
+	sum := 0.0
+	for _, logProbability := range logProbabilities {
+		sum += math.Exp(logProbability - maxLogProbability)
+	}
+	bestGroup, bestProbability := "", 0.0
+	for i, logProbability := range logProbabilities {
+		probability := math.Exp(logProbability-maxLogProbability) / sum
+		if probability > bestProbability {
+			bestGroup, bestProbability = model.sortedGroups[i], probability
+		}
+	}
+	return bestGroup, bestProbability
+}
+
+// ApplyClassifier moves transactions from `UnknownGroupName` groups into predicted groups (named
+// with `PredictedGroupSuffix`) if classifier is confident enough. Rest of transactions stay in
+// `UnknownGroupName` group or, if `isGroupAllUnknown` is false, are moved into groups with
+// name equal to `Transaction.Details`.
+func ApplyClassifier(
+	statistics []*IntervalStatistic,
+	classifier *TransactionClassifier,
+	minConfidence float64,
+	isGroupAllUnknown bool,
+) {
+	for _, s := range statistics {
+		for _, mapOfGroups := range []map[string]*Group{s.Income, s.Expense} {
+			unknownGroup, exists := mapOfGroups[UnknownGroupName]
+			if !exists {
+				continue
+			}
+			delete(mapOfGroups, UnknownGroupName)
+			for _, t := range unknownGroup.Transactions {
+				groupName, confidence := "", 0.0
+				if classifier != nil {
+					groupName, confidence = classifier.Predict(t)
+				}
+				if groupName != "" && confidence >= minConfidence {
+					name := groupName + PredictedGroupSuffix
+					addTransactionToGroup(mapOfGroups, name, t)
+					group := mapOfGroups[name]
+					count := float64(len(group.Transactions))
+					group.Confidence = (group.Confidence*(count-1) + confidence) / count
+				} else if isGroupAllUnknown {
+					addTransactionToGroup(mapOfGroups, UnknownGroupName, t)
+				} else {
+					addTransactionToGroup(mapOfGroups, t.Details, t)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestClassifier(t *testing.T) {
+	// Arrange
+	newExpense := func(details string) Transaction {
+		return Transaction{IsExpense: true, Date: now, Details: details, Amount: MoneyWith2DecimalPlaces{100}}
+	}
+	statistic := newIntervalStatistic()
+	statistic.Expense = map[string]*Group{
+		"Groceries": groupFromITs("Groceries", []Transaction{
+			newExpense("SAS SUPERMARKET ARABKIR"),
+			newExpense("SAS SUPERMARKET CENTER"),
+			newExpense("YEREVAN CITY SUPERMARKET"),
+		}),
+		"Taxi": groupFromITs("Taxi", []Transaction{
+			newExpense("YANDEX GO RIDE"),
+			newExpense("YANDEX GO TRIP"),
+		}),
+		UnknownGroupName: groupFromITs(UnknownGroupName, []Transaction{
+			newExpense("SAS SUPERMARKET KOMITAS"),
+			newExpense("YANDEX GO"),
+			newExpense("COMPLETELY NEW"),
+		}),
+	}
+	statistics := []*IntervalStatistic{statistic}
+
+	// Act
+	classifier := TrainClassifier(statistics)
+	ApplyClassifier(statistics, classifier, 0.8, true)
+
+	// Assert
+	groceries, exists := statistic.Expense["Groceries"+PredictedGroupSuffix]
+	if !exists || len(groceries.Transactions) != 1 || groceries.Transactions[0].Details != "SAS SUPERMARKET KOMITAS" {
+		t.Errorf("Expected predicted Groceries group with 1 transaction, got %+v", groceries)
+	} else if groceries.Confidence < 0.8 || groceries.Confidence > 1 {
+		t.Errorf("Expected confidence in 0.8..1, got %f", groceries.Confidence)
+	}
+	taxi, exists := statistic.Expense["Taxi"+PredictedGroupSuffix]
+	if !exists || len(taxi.Transactions) != 1 {
+		t.Errorf("Expected predicted Taxi group with 1 transaction, got %+v", taxi)
+	}
+	unknown, exists := statistic.Expense[UnknownGroupName]
+	if !exists || len(unknown.Transactions) != 1 || unknown.Transactions[0].Details != "COMPLETELY NEW" {
+		t.Errorf("Expected only 'COMPLETELY NEW' in unknown group, got %+v", unknown)
+	}
+	if len(statistic.Expense["Groceries"].Transactions) != 3 {
+		t.Errorf("Groups from rules should not be changed, got %+v", statistic.Expense["Groceries"])
+	}
+}
+
+func TestTrainClassifier_OnlyGroupedByRules(t *testing.T) {
+	// Arrange. Only "Taxi" group is chosen by rules for the whole transaction.
+	newExpense := func(details string) Transaction {
+		return Transaction{IsExpense: true, Date: now, Details: details, Amount: MoneyWith2DecimalPlaces{10000}}
+	}
+	refund := newExpense("YANDEX GO REFUND")
+	refund.IsRefund = true
+	manual := newExpense("YANDEX GO GIFT")
+	manual.Group = "Gifts"
+	portion := newExpense("YANDEX GO SHARED").splitPortion(5000)
+	statistic := newIntervalStatistic()
+	statistic.Expense = map[string]*Group{
+		"Taxi":        groupFromITs("Taxi", []Transaction{newExpense("YANDEX GO RIDE")}),
+		"Marketplace": groupFromITs("Marketplace", []Transaction{refund}),
+		"Gifts":       groupFromITs("Gifts", []Transaction{manual}),
+		"Shared":      groupFromITs("Shared", []Transaction{portion}),
+	}
+	statistic.Income = map[string]*Group{
+		RefundsGroupName: groupFromITs(RefundsGroupName, []Transaction{{Date: now, Details: "YANDEX GO",
+			Amount: MoneyWith2DecimalPlaces{10000}, IsRefund: true}}),
+	}
+
+	// Act
+	classifier := TrainClassifier([]*IntervalStatistic{statistic})
+
+	// Assert
+	if classifier == nil {
+		t.Fatal("TrainClassifier() = nil, want classifier")
+	}
+	if classifier.expense.documents != 1 || classifier.income.documents != 0 {
+		t.Errorf("Trained on %d expenses and %d incomes, want 1 and 0",
+			classifier.expense.documents, classifier.income.documents)
+	}
+	if group, _ := classifier.Predict(newExpense("YANDEX GO")); group != "Taxi" {
+		t.Errorf("Predict() = %q, want \"Taxi\"", group)
+	}
+}
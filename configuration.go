@@ -1,8 +1,11 @@
 package main
 
 import (
+	"bytes"
 	"fmt"
 	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 
 	"github.com/go-playground/validator/v10"
@@ -11,10 +14,92 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
+// AccountRules is a set of rules applied only to transactions from specified accounts.
+// Rules are checked in order: common `IgnoreSubstrings`, account `IgnoreSubstrings`, `IgnoreRules`,
+// account `GroupNamesToSubstrings`, common `GroupNamesToSubstrings`, `GroupNamesToRegexps`.
+type AccountRules struct {
+	Accounts               []string            `yaml:"accounts" validate:"required,min=1"`
+	IgnoreSubstrings       []string            `yaml:"ignoreSubstrings,omitempty"`
+	GroupNamesToSubstrings map[string][]string `yaml:"groupNamesToSubstrings,omitempty"`
+}
+
+// RuleConditions are conditions of `IgnoreRule` and `SplitRule`, transaction matches the rule if
+// it satisfies all specified conditions. Unlike substrings of group rules they may limit direction,
+// amount, accounts and dates of transactions. Amounts are positive.
+type RuleConditions struct {
+	Substring string                   `yaml:"substring,omitempty"`
+	Regexp    string                   `yaml:"regexp,omitempty"`
+	Direction string                   `yaml:"direction,omitempty" validate:"omitempty,oneof=income expense"`
+	MinAmount *MoneyWith2DecimalPlaces `yaml:"minAmount,omitempty"`
+	MaxAmount *MoneyWith2DecimalPlaces `yaml:"maxAmount,omitempty"`
+	Accounts  []string                 `yaml:"accounts,omitempty"`
+	// From and To are inclusive dates in "2006-01-02" format.
+	From string `yaml:"from,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	To   string `yaml:"to,omitempty" validate:"omitempty,datetime=2006-01-02"`
+}
+
+// IgnoreRule ignores transactions matching all conditions.
+type IgnoreRule struct {
+	// Name is shown in the "Ignored" section of the report instead of the list of conditions.
+	Name           string `yaml:"name,omitempty"`
+	RuleConditions `yaml:",inline"`
+}
+
+// SplitPart is a portion of split transaction assigned to the group. Portion is either `Percent` of
+// the transaction amount or fixed `Amount`. Part without both gets the rest of the amount.
+type SplitPart struct {
+	Group   string                   `yaml:"group" validate:"required"`
+	Percent float64                  `yaml:"percent,omitempty" validate:"min=0,max=100"`
+	Amount  *MoneyWith2DecimalPlaces `yaml:"amount,omitempty"`
+}
+
+// SplitRule splits transactions matching all conditions into portions of different groups, see `splitRule`.
+type SplitRule struct {
+	Name           string `yaml:"name,omitempty"`
+	RuleConditions `yaml:",inline"`
+	Parts          []SplitPart `yaml:"parts" validate:"required,min=1,dive"`
+}
+
+// Supported types of sources.
+const (
+	SourceTypeInecobankXml  = "inecobankXml"
+	SourceTypeAmeriaCsv     = "ameriaCsv"
+	SourceTypeMyAmeriaExcel = "myAmeriaExcel"
+	// SourceTypeManual is a file with transactions written by user, see `ManualFileParser`.
+	SourceTypeManual = "manual"
+	// SourceTypeAuto means to detect type of each file, see `fileParserTypes`.
+	SourceTypeAuto = "auto"
+)
+
+// Source is a set of files of the same type, usually exported from the same account.
+type Source struct {
+	Type string `yaml:"type" validate:"required,oneof=inecobankXml ameriaCsv myAmeriaExcel manual auto"`
+	// Globs are templates of file paths, "**" matches any number of nested directories.
+	Globs []string `yaml:"globs" validate:"required,min=1,dive,min=1"`
+	// Account is a label to use instead of account number from files. Helps to use short names
+	// in `accountRules` and to join a few accounts into one.
+	Account  string `yaml:"account,omitempty"`
+	Currency string `yaml:"currency,omitempty"`
+	// MyAccounts and IncomeSubstrings are used only by "myAmeriaExcel" type,
+	// see `myAmeriaMyAccounts` and `myAmeriaIncomeSubstrings` settings.
+	MyAccounts       []string `yaml:"myAccounts,omitempty"`
+	IncomeSubstrings []string `yaml:"incomeSubstrings,omitempty"`
+}
+
+// Refunds are settings to find refunds of expenses, see `DetectRefunds`.
+type Refunds struct {
+	MatchExpenses bool     `yaml:"matchExpenses,omitempty"`
+	Substrings    []string `yaml:"substrings,omitempty"`
+	MaxDays       uint     `yaml:"maxDays,omitempty" validate:"max=3650"`
+	Net           bool     `yaml:"net,omitempty"`
+}
+
 type Config struct {
-	InecobankStatementFilesGlob string              `yaml:"inecobankStatementFilesGlob" validate:"required,filepath,min=1"`
-	AmeriaCsvFilesGlob          string              `yaml:"ameriaCsvFilesGlob" validate:"required,filepath,min=1"`
-	MyAmeriaHistoryFilesGlob    string              `yaml:"myAmeriaHistoryFilesGlob" validate:"required,filepath,min=1"`
+	Include                     []string            `yaml:"include,omitempty"`
+	Sources                     []Source            `yaml:"sources,omitempty" validate:"dive"`
+	InecobankStatementFilesGlob string              `yaml:"inecobankStatementFilesGlob,omitempty" validate:"omitempty,filepath"`
+	AmeriaCsvFilesGlob          string              `yaml:"ameriaCsvFilesGlob,omitempty" validate:"omitempty,filepath"`
+	MyAmeriaHistoryFilesGlob    string              `yaml:"myAmeriaHistoryFilesGlob,omitempty" validate:"omitempty,filepath"`
 	MyAmeriaMyAccounts          []string            `yaml:"myAmeriaMyAccounts,omitempty"`
 	MyAmeriaIncomeSubstrings    []string            `yaml:"myAmeriaIncomeSubstrings,omitempty"`
 	DetailedOutput              bool                `yaml:"detailedOutput"`
@@ -22,22 +107,28 @@ type Config struct {
 	TimeZoneLocation            string              `yaml:"timeZoneLocation,omitempty" validate:"timezone"`
 	GroupAllUnknownTransactions bool                `yaml:"groupAllUnknownTransactions"`
 	IgnoreSubstrings            []string            `yaml:"ignoreSubstrings,omitempty"`
+	IgnoreRules                 []IgnoreRule        `yaml:"ignoreRules,omitempty" validate:"dive"`
+	SplitRules                  []SplitRule         `yaml:"splitRules,omitempty" validate:"dive"`
 	GroupNamesToSubstrings      map[string][]string `yaml:"groupNamesToSubstrings"`
+	GroupNamesToRegexps         map[string][]string `yaml:"groupNamesToRegexps,omitempty"`
+	AccountRules                []AccountRules      `yaml:"accountRules,omitempty" validate:"dive"`
+	OutputByAccounts            bool                `yaml:"outputByAccounts,omitempty"`
+	ClassifyUnknownTransactions bool                `yaml:"classifyUnknownTransactions,omitempty"`
+	ClassifierMinConfidence     float64             `yaml:"classifierMinConfidence,omitempty" validate:"min=0,max=1"`
+	DetectRecurring             bool                `yaml:"detectRecurring,omitempty"`
+	ForecastMonths              uint                `yaml:"forecastMonths,omitempty" validate:"max=120"`
+	CurrencyPrecisions          map[string]uint     `yaml:"currencyPrecisions,omitempty" validate:"dive,max=4"`
+	Refunds                     Refunds             `yaml:"refunds,omitempty"`
+	From                        string              `yaml:"from,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	To                          string              `yaml:"to,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	LastMonths                  uint                `yaml:"lastMonths,omitempty" validate:"excluded_with=From"`
+	Store                       string              `yaml:"store,omitempty"`
+	CashWithdrawalSubstrings    []string            `yaml:"cashWithdrawalSubstrings,omitempty"`
 }
 
 func readConfig(filename string) (*Config, error) {
-	buf, err := os.ReadFile(filename)
-	if err != nil {
-		return nil, err
-	}
-
 	cfg := &Config{}
-	decoder := yaml.NewDecoder(strings.NewReader(string(buf)))
-	decoder.KnownFields(true) // Disallow unknown fields
-	if err = decoder.Decode(cfg); err != nil {
-		if err.Error() == "EOF" {
-			return nil, fmt.Errorf("can't decode YAML from configuration file '%s': %v", filename, err)
-		}
+	if err := readConfigFile(filename, cfg, map[string]string{}, map[string]bool{}); err != nil {
 		return nil, err
 	}
 
@@ -45,6 +136,9 @@ func readConfig(filename string) (*Config, error) {
 	if cfg.MonthStartDayNumber == 0 {
 		cfg.MonthStartDayNumber = 1
 	}
+	if cfg.ClassifierMinConfidence == 0 {
+		cfg.ClassifierMinConfidence = 0.8
+	}
 	if len(cfg.TimeZoneLocation) == 0 {
 		tzname, err := tzlocal.RuntimeTZ()
 		if err != nil {
@@ -55,9 +149,224 @@ func readConfig(filename string) (*Config, error) {
 
 	// Validate.
 	validate := validator.New()
-	if err = validate.Struct(cfg); err != nil {
+	if err := validate.Struct(cfg); err != nil {
 		return nil, err
 	}
+	cfg.Sources = append(cfg.Sources, legacySources(cfg)...)
 
 	return cfg, nil
 }
+
+// legacySources converts settings used before `sources` into sources.
+func legacySources(cfg *Config) []Source {
+	sources := []Source{}
+	if cfg.InecobankStatementFilesGlob != "" {
+		sources = append(sources, Source{
+			Type:  SourceTypeInecobankXml,
+			Globs: []string{cfg.InecobankStatementFilesGlob},
+		})
+	}
+	if cfg.MyAmeriaHistoryFilesGlob != "" {
+		sources = append(sources, Source{
+			Type:             SourceTypeMyAmeriaExcel,
+			Globs:            []string{cfg.MyAmeriaHistoryFilesGlob},
+			MyAccounts:       cfg.MyAmeriaMyAccounts,
+			IncomeSubstrings: cfg.MyAmeriaIncomeSubstrings,
+		})
+	}
+	if cfg.AmeriaCsvFilesGlob != "" {
+		sources = append(sources, Source{
+			Type:  SourceTypeAmeriaCsv,
+			Globs: []string{cfg.AmeriaCsvFilesGlob},
+		})
+	}
+	return sources
+}
+
+// readConfigFile reads configuration file and files from its `include` list into `cfg`.
+// Lists are concatenated, groups with the same name get substrings from all files,
+// while other keys may be set only once. `origins` holds files where keys were set,
+// `visited` holds absolute paths of already read files to detect include cycles.
+func readConfigFile(filename string, cfg *Config, origins map[string]string, visited map[string]bool) error {
+	absPath, err := filepath.Abs(filename)
+	if err != nil {
+		return err
+	}
+	if visited[absPath] {
+		return fmt.Errorf("configuration file '%s' is included twice or recursively", filename)
+	}
+	visited[absPath] = true
+
+	buf, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	fileCfg := &Config{}
+	decoder := yaml.NewDecoder(bytes.NewReader(buf))
+	decoder.KnownFields(true) // Disallow unknown fields
+	if err = decoder.Decode(fileCfg); err != nil {
+		if err.Error() == "EOF" {
+			return fmt.Errorf("can't decode YAML from configuration file '%s': %v", filename, err)
+		}
+		return err
+	}
+	var document yaml.Node
+	if err = yaml.Unmarshal(buf, &document); err != nil {
+		return err
+	}
+	if err = mergeConfig(cfg, fileCfg, topLevelKeys(&document), filename, origins); err != nil {
+		return err
+	}
+
+	// Read included files, paths are relative to the including file.
+	for _, include := range fileCfg.Include {
+		pattern := include
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(filepath.Dir(filename), pattern)
+		}
+		files, err := filepath.Glob(pattern)
+		if err != nil {
+			return fmt.Errorf("wrong include '%s' in configuration file '%s': %w", include, filename, err)
+		}
+		if len(files) == 0 && !strings.ContainsAny(include, "*?[") {
+			return fmt.Errorf("can't find '%s' file included in configuration file '%s'", include, filename)
+		}
+		for _, file := range files {
+			if err = readConfigFile(file, cfg, origins, visited); err != nil {
+				return fmt.Errorf("'%s' included in '%s': %w", file, filename, err)
+			}
+		}
+	}
+	return nil
+}
+
+// topLevelKeys returns keys of the YAML mapping from the document.
+func topLevelKeys(document *yaml.Node) []string {
+	keys := []string{}
+	if document.Kind != yaml.DocumentNode || len(document.Content) != 1 {
+		return keys
+	}
+	root := document.Content[0]
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		keys = append(keys, root.Content[i].Value)
+	}
+	return keys
+}
+
+// mergeConfig copies values of specified keys from `src` into `dst` config.
+func mergeConfig(dst, src *Config, keys []string, filename string, origins map[string]string) error {
+	dstValue := reflect.ValueOf(dst).Elem()
+	srcValue := reflect.ValueOf(src).Elem()
+	fieldIndexes := map[string]int{}
+	for i := 0; i < dstValue.NumField(); i++ {
+		tag := strings.Split(dstValue.Type().Field(i).Tag.Get("yaml"), ",")[0]
+		fieldIndexes[tag] = i
+	}
+	for _, key := range keys {
+		i, exists := fieldIndexes[key]
+		if !exists || key == "include" {
+			continue
+		}
+		dstField, srcField := dstValue.Field(i), srcValue.Field(i)
+		switch srcField.Kind() {
+		case reflect.Slice:
+			dstField.Set(reflect.AppendSlice(dstField, srcField))
+		case reflect.Map:
+			if srcField.IsNil() {
+				continue
+			}
+			if dstField.IsNil() {
+				dstField.Set(reflect.MakeMap(dstField.Type()))
+			}
+			iter := srcField.MapRange()
+			for iter.Next() {
+				value := iter.Value()
+				if existing := dstField.MapIndex(iter.Key()); existing.IsValid() {
+					if value.Kind() != reflect.Slice {
+						return fmt.Errorf("'%v' of '%s' is set twice, the second time in '%s' configuration file",
+							iter.Key(), key, filename)
+					}
+					value = reflect.AppendSlice(existing, value)
+				}
+				dstField.SetMapIndex(iter.Key(), value)
+			}
+		default:
+			if origin, exists := origins[key]; exists {
+				return fmt.Errorf("'%s' is set in both '%s' and '%s' configuration files", key, origin, filename)
+			}
+			dstField.Set(srcField)
+			origins[key] = filename
+		}
+	}
+	return nil
+}
+
+// ConfigRule is a new rule to add into configuration file.
+type ConfigRule struct {
+	GroupName string
+	Pattern   string
+	IsRegexp  bool
+}
+
+// addRulesToConfigFile adds rules into 'groupNamesToSubstrings' or 'groupNamesToRegexps' sections
+// of the YAML configuration file. Works with YAML nodes to preserve comments and order in the file.
+func addRulesToConfigFile(filename string, rules []ConfigRule) error {
+	buf, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	var document yaml.Node
+	if err = yaml.Unmarshal(buf, &document); err != nil {
+		return fmt.Errorf("can't decode YAML from configuration file '%s': %w", filename, err)
+	}
+	if document.Kind != yaml.DocumentNode || len(document.Content) != 1 ||
+		document.Content[0].Kind != yaml.MappingNode {
+		return fmt.Errorf("configuration file '%s' doesn't contain YAML mapping", filename)
+	}
+	root := document.Content[0]
+
+	for _, rule := range rules {
+		sectionName := "groupNamesToSubstrings"
+		if rule.IsRegexp {
+			sectionName = "groupNamesToRegexps"
+		}
+		section := getOrAddMappingValue(root, sectionName, yaml.MappingNode)
+		group := getOrAddMappingValue(section, rule.GroupName, yaml.SequenceNode)
+		group.Content = append(group.Content, &yaml.Node{
+			Kind:  yaml.ScalarNode,
+			Tag:   "!!str",
+			Value: rule.Pattern,
+		})
+	}
+
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err = encoder.Encode(&document); err != nil {
+		return err
+	}
+	if err = encoder.Close(); err != nil {
+		return err
+	}
+	return os.WriteFile(filename, out.Bytes(), 0644)
+}
+
+// getOrAddMappingValue returns value node for the key in the YAML mapping node.
+// If there is no such key or value is empty then adds new value of specified kind.
+func getOrAddMappingValue(mapping *yaml.Node, key string, kind yaml.Kind) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value != key {
+			continue
+		}
+		value := mapping.Content[i+1]
+		if value.Kind == yaml.ScalarNode && (value.Tag == "!!null" || value.Value == "") {
+			value.Kind = kind
+			value.Tag = ""
+			value.Value = ""
+		}
+		return value
+	}
+	value := &yaml.Node{Kind: kind}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+	return value
+}
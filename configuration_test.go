@@ -3,6 +3,8 @@ package main
 import (
 	"errors"
 	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -296,6 +298,180 @@ groupNamesToSubstrings:
 	}
 }
 
+func TestReadConfig_Includes(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"config.yaml": `include:
+  - rules/*.yaml
+  - person.yaml
+inecobankStatementFilesGlob: "*.xml"
+ameriaCsvFilesGlob: "*.csv"
+myAmeriaHistoryFilesGlob: "*.xls"
+timeZoneLocation: "America/New_York"
+ignoreSubstrings:
+  - Ignore1
+groupNamesToSubstrings:
+  g1:
+    - Sub1
+`,
+		"rules/a.yaml": `groupNamesToSubstrings:
+  g1:
+    - Sub2
+  g2:
+    - Sub3
+`,
+		"rules/b.yaml": `ignoreSubstrings:
+  - Ignore2
+`,
+		"person.yaml": `detailedOutput: true
+myAmeriaMyAccounts:
+  - Account1
+`,
+	})
+
+	// Act
+	cfg, err := readConfig(filepath.Join(dir, "config.yaml"))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if !reflect.DeepEqual(cfg.GroupNamesToSubstrings, map[string][]string{"g1": {"Sub1", "Sub2"}, "g2": {"Sub3"}}) {
+		t.Errorf("Expected groups from all files, got %v", cfg.GroupNamesToSubstrings)
+	}
+	if !reflect.DeepEqual(cfg.IgnoreSubstrings, []string{"Ignore1", "Ignore2"}) {
+		t.Errorf("Expected ignore substrings from all files, got %v", cfg.IgnoreSubstrings)
+	}
+	if !cfg.DetailedOutput || len(cfg.MyAmeriaMyAccounts) != 1 {
+		t.Errorf("Expected settings from 'person.yaml', got %+v", cfg)
+	}
+}
+
+func TestReadConfig_IncludesErrors(t *testing.T) {
+	base := `inecobankStatementFilesGlob: "*.xml"
+ameriaCsvFilesGlob: "*.csv"
+myAmeriaHistoryFilesGlob: "*.xls"
+detailedOutput: true
+currencyPrecisions:
+  JPY: 0
+include:
+  - other.yaml
+`
+	tests := []struct {
+		name     string
+		other    string
+		expected string
+	}{
+		{"conflicting_key", "detailedOutput: false\n",
+			"'detailedOutput' is set in both"},
+		{"conflicting_map_key", "currencyPrecisions:\n  KWD: 3\n  JPY: 1\n",
+			"'JPY' of 'currencyPrecisions' is set twice"},
+		{"unknown_field", "groupsNamesToSubstrings:\n  g1: [Sub1]\n",
+			"line 1: field groupsNamesToSubstrings not found in type"},
+		{"cycle", "include: [config.yaml]\n",
+			"is included twice or recursively"},
+		{"missing_file", "include: [missing.yaml]\n",
+			"can't find 'missing.yaml' file included in configuration file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			dir := t.TempDir()
+			writeFiles(t, dir, map[string]string{"config.yaml": base, "other.yaml": tt.other})
+
+			// Act
+			_, err := readConfig(filepath.Join(dir, "config.yaml"))
+
+			// Assert
+			if err == nil {
+				t.Fatal("Expected error, but got no error")
+			}
+			checkErrorContainsSubstring(t, err, tt.expected)
+			checkErrorContainsSubstring(t, err, "other.yaml")
+		})
+	}
+}
+
+func TestReadConfig_Sources(t *testing.T) {
+	// Arrange
+	tempFile := createTempFileWithContent(
+		`sources:
+  - type: myAmeriaExcel
+    globs:
+      - "alex/**/History*.xls"
+      - "History*.xls"
+    account: Alex
+    currency: AMD
+    myAccounts: [Account1]
+inecobankStatementFilesGlob: "*.xml"
+timeZoneLocation: "America/New_York"
+`,
+	)
+	defer os.Remove(tempFile.Name())
+
+	// Act
+	cfg, err := readConfig(tempFile.Name())
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	expected := []Source{
+		{
+			Type:       SourceTypeMyAmeriaExcel,
+			Globs:      []string{"alex/**/History*.xls", "History*.xls"},
+			Account:    "Alex",
+			Currency:   "AMD",
+			MyAccounts: []string{"Account1"},
+		},
+		{Type: SourceTypeInecobankXml, Globs: []string{"*.xml"}},
+	}
+	if !reflect.DeepEqual(cfg.Sources, expected) {
+		t.Errorf("Expected sources %+v, got %+v", expected, cfg.Sources)
+	}
+}
+
+func TestReadConfig_SourcesErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"wrong_type", "sources:\n  - type: unknown\n    globs: ['*.xml']\n", "'Type' failed on the 'oneof' tag"},
+		{"no_globs", "sources:\n  - type: ameriaCsv\n", "'Globs' failed on the 'required' tag"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			tempFile := createTempFileWithContent(tt.content + "timeZoneLocation: \"America/New_York\"\n")
+			defer os.Remove(tempFile.Name())
+
+			// Act
+			_, err := readConfig(tempFile.Name())
+
+			// Assert
+			if err == nil {
+				t.Fatal("Expected error, but got no error")
+			}
+			checkErrorContainsSubstring(t, err, tt.expected)
+		})
+	}
+}
+
+// writeFiles writes files with the given content into the directory, creating nested directories.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
 // createTempFileWithContent creates a temporary file with the given content.
 func createTempFileWithContent(content string) *os.File {
 	tempFile, err := os.CreateTemp("", "test_config_*.yaml")
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// dateRangeFormat is a format of dates in `from` and `to` settings and flags.
+const dateRangeFormat = "2006-01-02"
+
+// DateRange restricts transactions which are aggregated. Zero `From` or `To` means no limit.
+type DateRange struct {
+	From time.Time
+	To   time.Time
+}
+
+// NewDateRange returns range from inclusive `from` and `to` dates in "2006-01-02" format or from
+// `lastMonths` number of month intervals up to the current one, including it. Month intervals start
+// on `monthStart` day like in `BuildMonthlyStatistic`. Empty arguments mean no limits.
+func NewDateRange(
+	from, to string,
+	lastMonths uint,
+	monthStart uint,
+	now time.Time,
+	timeLocation *time.Location,
+) (DateRange, error) {
+	result := DateRange{}
+	if lastMonths > 0 && from != "" {
+		return result, fmt.Errorf("'from' date and number of last months can't be used together")
+	}
+	if from != "" {
+		date, err := time.ParseInLocation(dateRangeFormat, from, timeLocation)
+		if err != nil {
+			return result, fmt.Errorf("wrong 'from' date '%s', expected YYYY-MM-DD: %w", from, err)
+		}
+		result.From = date
+	}
+	if to != "" {
+		date, err := time.ParseInLocation(dateRangeFormat, to, timeLocation)
+		if err != nil {
+			return result, fmt.Errorf("wrong 'to' date '%s', expected YYYY-MM-DD: %w", to, err)
+		}
+		result.To = date.AddDate(0, 0, 1).Add(-1 * time.Nanosecond)
+	}
+	if lastMonths > 0 {
+		result.From = monthIntervalStart(now.In(timeLocation), monthStart).AddDate(0, 1-int(lastMonths), 0)
+	}
+	if !result.From.IsZero() && !result.To.IsZero() && result.To.Before(result.From) {
+		return result, fmt.Errorf("'to' date is before %s start date", result.From.Format(dateRangeFormat))
+	}
+	return result, nil
+}
+
+// monthIntervalStart returns start of the month interval starting on `monthStart` day which
+// contains the date.
+func monthIntervalStart(date time.Time, monthStart uint) time.Time {
+	start := time.Date(date.Year(), date.Month(), int(monthStart), 0, 0, 0, 0, date.Location())
+	if start.After(date) {
+		start = start.AddDate(0, -1, 0)
+	}
+	return start
+}
+
+// IsSet checks that range limits dates.
+func (r DateRange) IsSet() bool {
+	return !r.From.IsZero() || !r.To.IsZero()
+}
+
+// Contains checks that date is in range. Calendar dates are compared because parsers build dates
+// in UTC while range limits are in the local time zone.
+func (r DateRange) Contains(date time.Time) bool {
+	day := date.Format(dateRangeFormat)
+	return (r.From.IsZero() || day >= r.From.Format(dateRangeFormat)) &&
+		(r.To.IsZero() || day <= r.To.Format(dateRangeFormat))
+}
+
+// Filter returns transactions from the range. Returns the same slice if range is not set.
+func (r DateRange) Filter(transactions []Transaction) []Transaction {
+	if !r.IsSet() {
+		return transactions
+	}
+	result := make([]Transaction, 0, len(transactions))
+	for _, t := range transactions {
+		if r.Contains(t.Date) {
+			result = append(result, t)
+		}
+	}
+	return result
+}
+
+func (r DateRange) String() string {
+	from, to := "", ""
+	if !r.From.IsZero() {
+		from = r.From.Format(dateRangeFormat)
+	}
+	if !r.To.IsZero() {
+		to = r.To.Format(dateRangeFormat)
+	}
+	return from + ".." + to
+}
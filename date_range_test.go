@@ -0,0 +1,92 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewDateRange(t *testing.T) {
+	now := time.Date(2024, 5, 10, 15, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name          string
+		from          string
+		to            string
+		lastMonths    uint
+		monthStart    uint
+		expected      string
+		expectedError string
+	}{
+		{"not_set", "", "", 0, 1, "..", ""},
+		{"from_to", "2024-01-01", "2024-03-31", 0, 1, "2024-01-01..2024-03-31", ""},
+		{"only_to", "", "2023-12-31", 0, 1, "..2023-12-31", ""},
+		{"last_months", "", "", 3, 1, "2024-03-01..", ""},
+		{"last_months_with_month_start_after_today", "", "", 1, 15, "2024-04-15..", ""},
+		{"last_months_with_month_start_before_today", "", "", 2, 5, "2024-04-05..", ""},
+		{"last_months_with_to", "", "2024-04-30", 2, 1, "2024-04-01..2024-04-30", ""},
+		{"last_months_with_from", "2024-01-01", "", 3, 1, "", "can't be used together"},
+		{"wrong_from", "01.01.2024", "", 0, 1, "", "wrong 'from' date"},
+		{"wrong_to", "", "2024-13-01", 0, 1, "", "wrong 'to' date"},
+		{"to_before_from", "2024-02-01", "2024-01-31", 0, 1, "", "'to' date is before 2024-02-01"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// Act
+			actual, err := NewDateRange(tt.from, tt.to, tt.lastMonths, tt.monthStart, now, time.UTC)
+
+			// Assert
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Errorf("NewDateRange() error = %v, want error with %q", err, tt.expectedError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewDateRange() failed: %v", err)
+			}
+			if actual.String() != tt.expected {
+				t.Errorf("NewDateRange() = %s, want %s", actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDateRange_Filter(t *testing.T) {
+	newTransaction := func(date time.Time) Transaction {
+		return Transaction{IsExpense: true, Date: date, Details: "MARKET", Amount: MoneyWith2DecimalPlaces{100}}
+	}
+	// Parsers build dates in UTC while range is in the local time zone.
+	transactions := []Transaction{
+		newTransaction(time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC)),
+		newTransaction(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)),
+		newTransaction(time.Date(2024, 2, 29, 23, 59, 59, 0, time.UTC)),
+		newTransaction(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)),
+	}
+	locations := []*time.Location{
+		time.UTC,
+		time.FixedZone("AMT", 4*60*60),
+		time.FixedZone("EST", -5*60*60),
+	}
+	for _, location := range locations {
+		t.Run(location.String(), func(t *testing.T) {
+			// Arrange
+			dateRange, err := NewDateRange("2024-02-01", "2024-02-29", 0, 1, time.Now(), location)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Act
+			actual := dateRange.Filter(transactions)
+
+			// Assert
+			if len(actual) != 2 || !actual[0].Date.Equal(transactions[1].Date) ||
+				!actual[1].Date.Equal(transactions[2].Date) {
+				t.Errorf("Filter() = %v, want transactions from February", actual)
+			}
+			if len(DateRange{}.Filter(transactions)) != len(transactions) {
+				t.Errorf("Filter() of not set range should return all transactions")
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Diagnostic is a problem with a file or with a row of the file which doesn't stop processing
+// of other files and rows.
+type Diagnostic struct {
+	// Severity is `SeverityError` for skipped files and rows, `SeverityWarning` for suspicious data.
+	Severity string `json:"severity"`
+	// File is a name of the file with problem.
+	File string `json:"file"`
+	// Line is a number of line or row in the file starting from 1, 0 for problems with the whole file.
+	Line int `json:"line,omitempty"`
+	// Raw is a content of the problem row as it is in the file.
+	Raw     string `json:"raw,omitempty"`
+	Message string `json:"message"`
+}
+
+func (d Diagnostic) String() string {
+	location := fmt.Sprintf("'%s'", d.File)
+	if d.Line > 0 {
+		location = fmt.Sprintf("%s:%d", location, d.Line)
+	}
+	return fmt.Sprintf("%s %s: %s", strings.ToUpper(d.Severity), location, d.Message)
+}
+
+// Diagnostics is a list of problems with rows which parser skipped. Parsers return it as an error
+// together with transactions from other rows.
+type Diagnostics []Diagnostic
+
+func (d Diagnostics) Error() string {
+	messages := make([]string, len(d))
+	for i, diagnostic := range d {
+		messages[i] = diagnostic.String()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// newRowDiagnostic returns error diagnostic about the row of the file which was skipped.
+func newRowDiagnostic(file string, line int, raw string, format string, a ...any) Diagnostic {
+	return Diagnostic{
+		Severity: SeverityError,
+		File:     file,
+		Line:     line,
+		Raw:      raw,
+		Message:  fmt.Sprintf(format, a...),
+	}
+}
+
+// newRowWarning returns warning diagnostic about suspicious row of the file which was parsed.
+func newRowWarning(file string, line int, raw string, format string, a ...any) Diagnostic {
+	diagnostic := newRowDiagnostic(file, line, raw, format, a...)
+	diagnostic.Severity = SeverityWarning
+	return diagnostic
+}
+
+// hasErrorDiagnostics checks that some diagnostic is an error, not a warning.
+func hasErrorDiagnostics(diagnostics []Diagnostic) bool {
+	for _, diagnostic := range diagnostics {
+		if diagnostic.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// DiagnosticsToString returns report section with all diagnostics or empty string if there are no them.
+func DiagnosticsToString(diagnostics []Diagnostic) string {
+	if len(diagnostics) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Diagnostics (%d):\n", len(diagnostics))
+	for _, diagnostic := range diagnostics {
+		sb.WriteString("  " + diagnostic.String() + "\n")
+		if diagnostic.Raw != "" {
+			sb.WriteString("    " + diagnostic.Raw + "\n")
+		}
+	}
+	return sb.String()
+}
+
+// DiagnosticsToJson returns diagnostics as JSON array.
+func DiagnosticsToJson(diagnostics []Diagnostic) (string, error) {
+	if diagnostics == nil {
+		diagnostics = []Diagnostic{}
+	}
+	buf, err := json.MarshalIndent(diagnostics, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(buf) + "\n", nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDiagnosticsToString(t *testing.T) {
+	// Arrange
+	diagnostics := []Diagnostic{
+		{Severity: SeverityWarning, File: "empty.csv", Message: "Can't find transactions."},
+		{Severity: SeverityError, File: "a.csv", Line: 3, Raw: "32/08/2023\t1.00", Message: "failed to parse date"},
+	}
+
+	// Act
+	actual := DiagnosticsToString(diagnostics)
+
+	// Assert
+	expected := "Diagnostics (2):\n" +
+		"  WARNING 'empty.csv': Can't find transactions.\n" +
+		"  ERROR 'a.csv':3: failed to parse date\n" +
+		"    32/08/2023\t1.00\n"
+	if actual != expected {
+		t.Errorf("DiagnosticsToString() = %q, want %q", actual, expected)
+	}
+	if empty := DiagnosticsToString(nil); empty != "" {
+		t.Errorf("DiagnosticsToString(nil) = %q, want empty string", empty)
+	}
+}
+
+func TestDiagnosticsToJson(t *testing.T) {
+	// Arrange
+	diagnostics := []Diagnostic{
+		{Severity: SeverityError, File: "a.csv", Line: 3, Raw: "raw", Message: "failed"},
+	}
+
+	// Act
+	actual, err := DiagnosticsToJson(diagnostics)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("DiagnosticsToJson() failed: %v", err)
+	}
+	var parsed []map[string]any
+	if err := json.Unmarshal([]byte(actual), &parsed); err != nil {
+		t.Fatalf("Can't parse JSON %s: %v", actual, err)
+	}
+	if len(parsed) != 1 || parsed[0]["severity"] != "error" || parsed[0]["file"] != "a.csv" ||
+		parsed[0]["line"] != float64(3) || parsed[0]["raw"] != "raw" || parsed[0]["message"] != "failed" {
+		t.Errorf("DiagnosticsToJson() = %s", actual)
+	}
+	if empty, _ := DiagnosticsToJson(nil); empty != "[]\n" {
+		t.Errorf("DiagnosticsToJson(nil) = %q, want empty array", empty)
+	}
+}
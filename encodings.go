@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+	textunicode "golang.org/x/text/encoding/unicode"
+)
+
+const (
+	EncodingUTF8        = "UTF-8"
+	EncodingUTF16LE     = "UTF-16LE"
+	EncodingUTF16BE     = "UTF-16BE"
+	EncodingWindows1251 = "Windows-1251"
+	EncodingArmscii8    = "ARMSCII-8"
+)
+
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// decodeText detects encoding of the text and returns the text in UTF-8 without byte order mark
+// with name of the detected encoding. UTF-8 and UTF-16 are detected by byte order mark, UTF-8
+// without it by validity. Otherwise the text is in one of legacy 8-bit encodings used by banks in
+// the region: Windows-1251 or ARMSCII-8, chosen by which of them gives more natural text.
+func decodeText(data []byte) ([]byte, string, error) {
+	switch {
+	case bytes.HasPrefix(data, bomUTF8):
+		return data[len(bomUTF8):], EncodingUTF8, nil
+	case bytes.HasPrefix(data, bomUTF16LE):
+		decoded, err := textunicode.UTF16(textunicode.LittleEndian, textunicode.ExpectBOM).NewDecoder().Bytes(data)
+		if err != nil {
+			return nil, EncodingUTF16LE, fmt.Errorf("can't decode %s text: %w", EncodingUTF16LE, err)
+		}
+		return decoded, EncodingUTF16LE, nil
+	case bytes.HasPrefix(data, bomUTF16BE):
+		decoded, err := textunicode.UTF16(textunicode.BigEndian, textunicode.ExpectBOM).NewDecoder().Bytes(data)
+		if err != nil {
+			return nil, EncodingUTF16BE, fmt.Errorf("can't decode %s text: %w", EncodingUTF16BE, err)
+		}
+		return decoded, EncodingUTF16BE, nil
+	case utf8.Valid(data):
+		return data, EncodingUTF8, nil
+	}
+	windows1251, err := charmap.Windows1251.NewDecoder().Bytes(data)
+	if err != nil {
+		return nil, EncodingWindows1251, fmt.Errorf("can't decode %s text: %w", EncodingWindows1251, err)
+	}
+	armscii8 := decodeArmscii8(data)
+	if countCaseBreaks(string(armscii8)) < countCaseBreaks(string(windows1251)) {
+		return armscii8, EncodingArmscii8, nil
+	}
+	return windows1251, EncodingWindows1251, nil
+}
+
+// countCaseBreaks returns number of upper case letters following lower case letters. Natural text
+// rarely has them while text decoded with wrong 8-bit encoding has random mix of cases.
+func countCaseBreaks(text string) int {
+	count := 0
+	var previous rune
+	for _, r := range text {
+		if unicode.IsLower(previous) && unicode.IsUpper(r) {
+			count++
+		}
+		previous = r
+	}
+	return count
+}
+
+// armscii8Table maps bytes 0xA0-0xFF of ARMSCII-8 encoding to runes.
+var armscii8Table = func() [0x60]rune {
+	table := [0x60]rune{
+		' ', utf8.RuneError, 'և', '։', ')', '(', '»', '«',
+		'—', '.', '՝', ',', '-', '֊', '…', '՜',
+		'՛', '՞',
+	}
+	// Armenian letters go in pairs of upper and lower case ones.
+	for i := 0; i <= 0x0556-0x0531; i++ {
+		table[0x12+2*i] = rune(0x0531 + i)
+		table[0x13+2*i] = rune(0x0561 + i)
+	}
+	table[0x5E] = '՚'
+	table[0x5F] = utf8.RuneError
+	return table
+}()
+
+// decodeArmscii8 converts text in ARMSCII-8 encoding to UTF-8.
+func decodeArmscii8(data []byte) []byte {
+	var sb strings.Builder
+	for _, b := range data {
+		if b < 0xA0 {
+			sb.WriteRune(rune(b))
+		} else {
+			sb.WriteRune(armscii8Table[b-0xA0])
+		}
+	}
+	return []byte(sb.String())
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"testing"
+	"unicode/utf16"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+const (
+	russianText  = "Оплата услуг связи, Ереван"
+	armenianText = "Աշխատավարձ օգոստոս ամսվա համար"
+)
+
+func TestDecodeText(t *testing.T) {
+	windows1251, err := charmap.Windows1251.NewEncoder().Bytes([]byte(russianText))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name             string
+		data             []byte
+		expected         string
+		expectedEncoding string
+	}{
+		{"utf8", []byte(armenianText), armenianText, EncodingUTF8},
+		{"utf8_bom", append([]byte("\ufeff"), armenianText...), armenianText, EncodingUTF8},
+		{"utf16le_bom", encodeUTF16LE("\ufeff" + armenianText), armenianText, EncodingUTF16LE},
+		{"utf16be_bom", encodeUTF16BE("\ufeff" + armenianText), armenianText, EncodingUTF16BE},
+		{"utf16le_odd_length", append(encodeUTF16LE("\ufeffabc"), 'd'), "abc\ufffd", EncodingUTF16LE},
+		{"windows1251", windows1251, russianText, EncodingWindows1251},
+		{"armscii8", encodeArmscii8(t, armenianText), armenianText, EncodingArmscii8},
+		{"armscii8_punctuation", encodeArmscii8(t, "«Ամերիա» բանկ։"), "«Ամերիա» բանկ։", EncodingArmscii8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// Act
+			actual, encoding, err := decodeText(tt.data)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("decodeText() failed: %v", err)
+			}
+			if string(actual) != tt.expected {
+				t.Errorf("decodeText() = %q, want %q", actual, tt.expected)
+			}
+			if encoding != tt.expectedEncoding {
+				t.Errorf("decodeText() encoding = %s, want %s", encoding, tt.expectedEncoding)
+			}
+		})
+	}
+}
+
+func encodeUTF16BE(text string) []byte {
+	result := []byte{}
+	for _, unit := range utf16.Encode([]rune(text)) {
+		result = append(result, byte(unit>>8), byte(unit))
+	}
+	return result
+}
+
+func encodeArmscii8(t *testing.T, text string) []byte {
+	bytesByRune := map[rune]byte{}
+	for i, r := range armscii8Table {
+		bytesByRune[r] = byte(0xA0 + i)
+	}
+	result := []byte{}
+	for _, r := range text {
+		if r < 0x80 {
+			result = append(result, byte(r))
+			continue
+		}
+		b, ok := bytesByRune[r]
+		if !ok {
+			t.Fatalf("'%c' is not in ARMSCII-8", r)
+		}
+		result = append(result, b)
+	}
+	return result
+}
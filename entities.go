@@ -2,24 +2,81 @@ package main
 
 import "time"
 
-// MoneyWith2DecimalPlaces is a wrapper to parse money from "1,500.00" or "1,500" to 150000.
+// MoneyWith2DecimalPlaces is an exact amount of money in 1/10000 of the currency unit, i.e. with
+// `amountsPrecision` (4) digits after the decimal separator, e.g. "1,500.25" is 15002500.
+// The name is historical, reports show 2 digits by default.
 type MoneyWith2DecimalPlaces struct {
 	int
 }
 
 const OutputDateFormat = "2006-01-02"
 
+// Transaction is a transaction parsed from some file. JSON tags are used by `TransactionStore`
+// which keeps only parsed fields, calculated ones are skipped.
 type Transaction struct {
-	IsExpense bool
-	Date      time.Time
-	Details   string
-	Amount    MoneyWith2DecimalPlaces
+	IsExpense bool                    `json:"isExpense"`
+	Date      time.Time               `json:"date"`
+	Details   string                  `json:"details"`
+	Amount    MoneyWith2DecimalPlaces `json:"amount"`
+	Account   string                  `json:"account,omitempty"`
+	// Currency is a currency of the account, empty if unknown.
+	Currency string `json:"currency,omitempty"`
+	// ReceiverAccount is an account of the other side: receiver for expenses and payer for incomes.
+	ReceiverAccount string `json:"receiverAccount,omitempty"`
+	// Source is a description of file and parser the transaction is parsed from.
+	Source string `json:"source,omitempty"`
+	// Balance is a balance of the account after transaction. Is nil if unknown.
+	Balance *MoneyWith2DecimalPlaces `json:"-"`
+	// IsRefund is true for incomes which return money of some expense, see `DetectRefunds`.
+	IsRefund bool `json:"-"`
+	// RefundedDetails is `Details` of the expense returned by refund, empty if unknown.
+	RefundedDetails string `json:"-"`
+	// Group is a name of the group chosen manually, overrides rules. See `ManualFileParser`.
+	Group string `json:"group,omitempty"`
+	// IsCash is true for manual expenses paid by cash from some withdrawal, see `AllocateCashSpending`.
+	IsCash bool `json:"isCash,omitempty"`
+	// Allocated is a part of cash withdrawal spent on manual `IsCash` expenses which are counted
+	// in their own groups, so the group of withdrawal gets only the rest.
+	Allocated MoneyWith2DecimalPlaces `json:"-"`
+	// SplitOf is the whole amount of transaction if this one is its portion made by `SplitRule`.
+	SplitOf MoneyWith2DecimalPlaces `json:"-"`
+}
+
+// isNettedRefund checks that transaction is a refund put into expense group to decrease it.
+func (t *Transaction) isNettedRefund() bool {
+	return t.IsRefund && t.IsExpense
+}
+
+// groupAmount returns amount which transaction adds to total of its group.
+func (t *Transaction) groupAmount() int {
+	if t.isNettedRefund() {
+		return -t.Amount.int
+	}
+	return t.Amount.int - t.Allocated.int
+}
+
+// AccountStatement is balances of the account for the period covered by some statement file.
+type AccountStatement struct {
+	Source         string                  `json:"source,omitempty"`
+	Account        string                  `json:"account"`
+	Currency       string                  `json:"currency,omitempty"`
+	Start          time.Time               `json:"start"`
+	End            time.Time               `json:"end"`
+	OpeningBalance MoneyWith2DecimalPlaces `json:"openingBalance"`
+	ClosingBalance MoneyWith2DecimalPlaces `json:"closingBalance"`
+	TotalIncome    MoneyWith2DecimalPlaces `json:"totalIncome"`
+	TotalExpense   MoneyWith2DecimalPlaces `json:"totalExpense"`
 }
 
 type Group struct {
-	Name         string
-	Total        MoneyWith2DecimalPlaces
+	Name  string
+	Total MoneyWith2DecimalPlaces
+	// Refunds is a sum of refunds netted against the group, `Total` is already decreased by it.
+	Refunds      MoneyWith2DecimalPlaces
 	Transactions []Transaction
+	// Confidence is an average probability of transactions to belong to the group
+	// if they were assigned by classifier. Is 0 for groups from rules.
+	Confidence float64
 }
 
 type IntervalStatistic struct {
@@ -27,4 +84,8 @@ type IntervalStatistic struct {
 	End     time.Time
 	Income  map[string]*Group
 	Expense map[string]*Group
+	// Ignored is a map of ignore rule to transactions it excluded from `Income` and `Expense`.
+	Ignored map[string]*Group
+	// Balances is a map of account to its balance at the end of interval.
+	Balances map[string]MoneyWith2DecimalPlaces
 }
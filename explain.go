@@ -0,0 +1,188 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// transactionQuery selects transactions to explain.
+type transactionQuery struct {
+	date      *time.Time
+	amount    *MoneyWith2DecimalPlaces
+	substring string
+}
+
+// parseTransactionQuery parses query like "2023-08-05", "2023-08-05 499.50" or any substring.
+func parseTransactionQuery(query string) transactionQuery {
+	fields := strings.Fields(query)
+	if len(fields) == 0 || len(fields) > 2 {
+		return transactionQuery{substring: query}
+	}
+	date, err := time.Parse(OutputDateFormat, fields[0])
+	if err != nil {
+		return transactionQuery{substring: query}
+	}
+	result := transactionQuery{date: &date}
+	if len(fields) == 2 {
+		var amount MoneyWith2DecimalPlaces
+		if err := amount.UnmarshalText([]byte(fields[1])); err != nil {
+			return transactionQuery{substring: query}
+		}
+		result.amount = &amount
+	}
+	return result
+}
+
+func (q transactionQuery) matches(t Transaction) bool {
+	if q.date != nil {
+		if t.Date.Format(OutputDateFormat) != q.date.Format(OutputDateFormat) {
+			return false
+		}
+		return q.amount == nil || q.amount.int == t.Amount.int
+	}
+	return strings.Contains(strings.ToLower(t.Details), strings.ToLower(q.substring))
+}
+
+// findTransactionGroup returns interval and group containing the transaction or its first portion.
+func findTransactionGroup(statistics []*IntervalStatistic, t Transaction) (*IntervalStatistic, *Group) {
+	key := newTransactionKey(t)
+	for _, s := range statistics {
+		mapOfGroups := s.Income
+		if t.IsExpense {
+			mapOfGroups = s.Expense
+		}
+		for _, group := range mapOfGroups {
+			for _, groupTransaction := range group.Transactions {
+				if newTransactionKey(groupTransaction.unsplit()) == key {
+					return s, group
+				}
+			}
+		}
+	}
+	return nil, nil
+}
+
+// ExplainTransactions finds transactions by query (see `parseTransactionQuery`) and explains
+// which parser produced them, which rules matched them and where they are in the statistic.
+func ExplainTransactions(
+	query string,
+	transactions []Transaction,
+	groupExtractorFactory StatisticBuilderFactory,
+	config *Config,
+	timeZone *time.Location,
+) (string, error) {
+	extractor, ok := groupExtractorFactory(time.Time{}, time.Time{}).(groupExtractorByDetailsSubstrings)
+	if !ok {
+		return "", fmt.Errorf("statistic builder doesn't support explanation")
+	}
+	statistics, err := BuildMonthlyStatistic(
+		transactions,
+		groupExtractorFactory,
+		config.MonthStartDayNumber,
+		timeZone,
+	)
+	if err != nil {
+		return "", err
+	}
+	if config.ClassifyUnknownTransactions {
+		ApplyClassifier(
+			statistics,
+			TrainClassifier(statistics),
+			config.ClassifierMinConfidence,
+			config.GroupAllUnknownTransactions,
+		)
+	}
+
+	q := parseTransactionQuery(query)
+	var sb strings.Builder
+	found := 0
+	for _, t := range transactions {
+		if !q.matches(t) {
+			continue
+		}
+		found++
+		direction := "income"
+		if t.IsExpense {
+			direction = "expense"
+		}
+		fmt.Fprintf(&sb, "\n%s\n  Parsed from %s as %s", t.String(), t.Source, direction)
+		if t.Account != "" {
+			fmt.Fprintf(&sb, " of %s account", t.Account)
+		}
+		sb.WriteString(".\n")
+
+		// Refunds and manual groups are handled before rules except ignore ones, see `HandleTransaction`.
+		statisticTrans, ruleTrans := t, t
+		isRefund := t.IsRefund && extractor.matchIgnoreRule(t) == nil
+		isGrossRefund := isRefund && !extractor.isNetRefunds
+		switch {
+		case isGrossRefund:
+			sb.WriteString("  Detected as refund, rules are not checked.\n")
+		case isRefund:
+			statisticTrans, ruleTrans.Details = asNettedRefund(t)
+			ruleTrans.IsExpense = true
+			fmt.Fprintf(&sb, "  Detected as refund, it decreases the expense group chosen for %q.\n", ruleTrans.Details)
+		}
+		isManualGroup := t.Group != "" && !isGrossRefund
+		if isManualGroup {
+			fmt.Fprintf(&sb, "  Group '%s' is set manually, rules are not checked.\n", t.Group)
+		}
+
+		// Rules.
+		var matches []RuleMatch
+		if !isGrossRefund && !isManualGroup {
+			matches = extractor.matchRules(ruleTrans)
+			if len(matches) == 0 {
+				sb.WriteString("  No rules match it.\n")
+			} else {
+				sb.WriteString("  Matched rules (ignore rules first, then account specific substrings, common" +
+					" substrings from the longest, regular expressions; the first one wins):\n")
+				for i, match := range matches {
+					result := "ignore"
+					if !match.IsIgnore {
+						result = fmt.Sprintf("group '%s'", match.GroupName)
+					}
+					winner := ""
+					if i == 0 {
+						winner = " <- WINS"
+					}
+					fmt.Fprintf(&sb, "    %d. %s %q -> %s%s\n", i+1, match.Section, match.Pattern, result, winner)
+				}
+			}
+		}
+
+		isIgnored := len(matches) > 0 && matches[0].IsIgnore
+		if rule := extractor.matchSplitRule(t); rule != nil && !isManualGroup && !isIgnored {
+			fmt.Fprintf(&sb, "  Split by split rule %q, see \"part of\" transactions in the detailed listing.\n",
+				rule.label)
+		}
+
+		// Result.
+		interval, group := findTransactionGroup(statistics, statisticTrans)
+		switch {
+		case isIgnored:
+			sb.WriteString("  Result: ignored, i.e. not included into statistic.\n")
+		case group == nil:
+			sb.WriteString("  Result: not found in statistic.\n")
+		default:
+			reason := "by rule"
+			switch {
+			case isGrossRefund:
+				reason = "as refund"
+			case isManualGroup:
+				reason = "set manually"
+			case group.Confidence > 0:
+				reason = "by classifier"
+			case len(matches) == 0:
+				reason = "because no rules match"
+			}
+			fmt.Fprintf(&sb, "  Result: group '%s' (%s) in %s..%s interval.\n",
+				group.Name, reason, interval.Start.Format(OutputDateFormat), interval.End.Format(OutputDateFormat))
+		}
+	}
+	if found == 0 {
+		return fmt.Sprintf("No transactions found by '%s'.\n", query), nil
+	}
+	return fmt.Sprintf("Found %d transaction(s) by '%s':\n%s", found, query, sb.String()), nil
+}
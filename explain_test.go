@@ -0,0 +1,154 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExplainTransactions(t *testing.T) {
+	// Arrange
+	date := time.Date(2023, time.August, 5, 0, 0, 0, 0, time.UTC)
+	transactions := []Transaction{
+		{IsExpense: true, Date: date, Details: "YANDEX.GO, YEREVAN", Amount: MoneyWith2DecimalPlaces{4995000}, Source: "'a.xml' by InecoXmlParser"},
+		{IsExpense: true, Date: date, Details: "SAS SUPERMARKET", Amount: MoneyWith2DecimalPlaces{10000000}, Source: "'a.xml' by InecoXmlParser"},
+		{IsExpense: true, Date: date.AddDate(0, 0, 1), Details: "RENT", Amount: MoneyWith2DecimalPlaces{10000}, Source: "'a.xml' by InecoXmlParser"},
+		{IsExpense: true, Date: date.AddDate(0, 0, 2), Details: "YANDEX tips", Amount: MoneyWith2DecimalPlaces{50000}, Group: "Gifts"},
+	}
+	config := &Config{MonthStartDayNumber: 1, GroupAllUnknownTransactions: true}
+	factory, err := NewStatisticBuilderByDetailsSubstrings(&Config{
+		GroupNamesToSubstrings:      map[string][]string{"Taxi": {"YANDEX"}, "Groceries": {"YANDEX.GO"}},
+		GroupAllUnknownTransactions: true,
+		IgnoreSubstrings:            []string{"SUPERMARKET"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		query    string
+		expected []string
+	}{
+		{
+			name:  "by_date_and_amount",
+			query: "2023-08-05 499.50",
+			expected: []string{
+				"Found 1 transaction(s)",
+				"Parsed from 'a.xml' by InecoXmlParser as expense.",
+				"1. groupNamesToSubstrings \"YANDEX.GO\" -> group 'Groceries' <- WINS",
+				"2. groupNamesToSubstrings \"YANDEX\" -> group 'Taxi'\n",
+				"Result: group 'Groceries' (by rule) in 2023-08-01..2023-08-31 interval.",
+			},
+		},
+		{
+			name:  "by_substring_ignored",
+			query: "supermarket",
+			expected: []string{
+				"1. ignoreSubstrings \"SUPERMARKET\" -> ignore <- WINS",
+				"Result: ignored",
+			},
+		},
+		{
+			name:  "unknown",
+			query: "2023-08-06",
+			expected: []string{
+				"No rules match it.",
+				"Result: group 'unknown' (because no rules match)",
+			},
+		},
+		{
+			name:  "manual_group",
+			query: "2023-08-07",
+			expected: []string{
+				"Group 'Gifts' is set manually, rules are not checked.",
+				"Result: group 'Gifts' (set manually)",
+			},
+		},
+		{
+			name:     "not_found",
+			query:    "2023-08-05 1.00",
+			expected: []string{"No transactions found"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// Act
+			actual, err := ExplainTransactions(tt.query, transactions, factory, config, time.UTC)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("ExplainTransactions() failed: %v", err)
+			}
+			for _, expected := range tt.expected {
+				if !strings.Contains(actual, expected) {
+					t.Errorf("Expected '%s' in:\n%s", expected, actual)
+				}
+			}
+		})
+	}
+}
+
+func TestExplainTransactions_Refunds(t *testing.T) {
+	// Arrange
+	date := time.Date(2023, time.August, 5, 0, 0, 0, 0, time.UTC)
+	transactions := []Transaction{
+		{IsExpense: true, Date: date, Details: "WILDBERRIES order", Amount: MoneyWith2DecimalPlaces{10000000}},
+		{
+			Date:            date.AddDate(0, 0, 3),
+			Details:         "Return from marketplace",
+			Amount:          MoneyWith2DecimalPlaces{4000000},
+			IsRefund:        true,
+			RefundedDetails: "WILDBERRIES order",
+		},
+	}
+	config := &Config{MonthStartDayNumber: 1, GroupAllUnknownTransactions: true}
+	tests := []struct {
+		name         string
+		isNetRefunds bool
+		expected     []string
+	}{
+		{
+			name:         "gross",
+			isNetRefunds: false,
+			expected: []string{
+				"Detected as refund, rules are not checked.",
+				"Result: group 'refunds' (as refund)",
+			},
+		},
+		{
+			name:         "net",
+			isNetRefunds: true,
+			expected: []string{
+				"Detected as refund, it decreases the expense group chosen for \"WILDBERRIES order\".",
+				"1. groupNamesToSubstrings \"WILDBERRIES\" -> group 'Marketplaces' <- WINS",
+				"Result: group 'Marketplaces' (by rule)",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			factory, err := NewStatisticBuilderByDetailsSubstrings(&Config{
+				GroupNamesToSubstrings:      map[string][]string{"Marketplaces": {"WILDBERRIES"}},
+				GroupAllUnknownTransactions: true,
+				Refunds:                     Refunds{Net: tt.isNetRefunds},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Act
+			actual, err := ExplainTransactions("2023-08-08", transactions, factory, config, time.UTC)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("ExplainTransactions() failed: %v", err)
+			}
+			for _, expected := range tt.expected {
+				if !strings.Contains(actual, expected) {
+					t.Errorf("Expected '%s' in:\n%s", expected, actual)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// IntervalForecast is a projected income and expense for the future interval.
+type IntervalForecast struct {
+	Start            time.Time
+	End              time.Time
+	Income           MoneyWith2DecimalPlaces
+	Expense          MoneyWith2DecimalPlaces
+	RecurringIncome  MoneyWith2DecimalPlaces
+	RecurringExpense MoneyWith2DecimalPlaces
+}
+
+// Net returns difference between projected income and expense.
+func (f IntervalForecast) Net() MoneyWith2DecimalPlaces {
+	return MoneyWith2DecimalPlaces{f.Income.int - f.Expense.int}
+}
+
+// transactionKey identifies `Transaction` to compare transactions from different lists.
+type transactionKey struct {
+	isExpense bool
+	date      time.Time
+	details   string
+	amount    int
+}
+
+func newTransactionKey(t Transaction) transactionKey {
+	return transactionKey{t.IsExpense, t.Date, t.Details, t.Amount.int}
+}
+
+// BuildForecast projects income and expense for `intervalsCount` months following the last
+// interval from `statistics`. Projection consists of:
+//  1. Not stopped recurring series from `series` placed on their expected dates.
+//  2. Per-group averages of all other transactions from `statistics`.
+func BuildForecast(
+	statistics []*IntervalStatistic,
+	series []RecurringSeries,
+	intervalsCount uint,
+	monthStart uint,
+	timeLocation *time.Location,
+) []IntervalForecast {
+	if len(statistics) == 0 || intervalsCount == 0 {
+		return []IntervalForecast{}
+	}
+
+	// Collect transactions from recurring series to don't count them twice.
+	recurring := map[transactionKey]int{}
+	for _, s := range series {
+		for _, t := range s.Transactions {
+			recurring[newTransactionKey(t)]++
+		}
+	}
+
+	// Calculate average per interval for not recurring transactions.
+	// Sum of per-group averages is the same as average of sums so don't keep groups.
+	nonRecurringIncome, nonRecurringExpense := 0, 0
+	for _, stat := range statistics {
+		nonRecurringIncome += sumNonRecurring(stat.Income, recurring)
+		nonRecurringExpense += sumNonRecurring(stat.Expense, recurring)
+	}
+	averageIncome := nonRecurringIncome / len(statistics)
+	averageExpense := nonRecurringExpense / len(statistics)
+
+	// Project each interval.
+	last := statistics[len(statistics)-1]
+	start := last.End.Add(time.Nanosecond)
+	start = time.Date(start.Year(), start.Month(), int(monthStart), 0, 0, 0, 0, timeLocation)
+	result := make([]IntervalForecast, 0, intervalsCount)
+	for i := uint(0); i < intervalsCount; i++ {
+		end := start.AddDate(0, 1, 0).Add(-1 * time.Nanosecond)
+		forecast := IntervalForecast{Start: start, End: end}
+		for _, s := range series {
+			if s.IsStopped {
+				continue
+			}
+			amount := s.LastAmount.int * countOccurrences(s, start, end)
+			if s.IsExpense {
+				forecast.RecurringExpense.int += amount
+			} else {
+				forecast.RecurringIncome.int += amount
+			}
+		}
+		forecast.Income.int = averageIncome + forecast.RecurringIncome.int
+		forecast.Expense.int = averageExpense + forecast.RecurringExpense.int
+		result = append(result, forecast)
+		start = end.Add(time.Nanosecond)
+	}
+	return result
+}
+
+// sumNonRecurring sums transactions which are not in `recurring`. Portions of split transaction
+// are in different groups but the whole transaction is counted in `recurring` only once.
+func sumNonRecurring(mapOfGroups map[string]*Group, recurring map[transactionKey]int) int {
+	sum := 0
+	recurringSplits := map[transactionKey]bool{}
+	for _, group := range mapOfGroups {
+		for _, t := range group.Transactions {
+			key := newTransactionKey(t.unsplit())
+			if t.SplitOf.int > 0 && recurringSplits[key] {
+				continue
+			}
+			if recurring[key] > 0 {
+				if t.SplitOf.int > 0 {
+					recurringSplits[key] = true
+				}
+				recurring[key]--
+				continue
+			}
+			sum += t.groupAmount()
+		}
+	}
+	return sum
+}
+
+// countOccurrences returns how many times recurring series happens in [start, end] interval.
+func countOccurrences(s RecurringSeries, start, end time.Time) int {
+	count := 0
+	last := s.Transactions[len(s.Transactions)-1].Date
+	for i := 1; ; i++ {
+		date := s.Cadence.addTo(last, i)
+		if date.After(end) {
+			return count
+		}
+		if !date.Before(start) {
+			count++
+		}
+	}
+}
+
+// ForecastToString converts list of `IntervalForecast` to human readable string.
+func ForecastToString(forecast []IntervalForecast) string {
+	lines := make([]string, len(forecast))
+	cumulative := 0
+	for i, f := range forecast {
+		cumulative += f.Net().int
+		lines[i] = fmt.Sprintf(
+			"\n  %s..%s: income=%s (recurring %s), expense=%s (recurring %s), net=%s, cumulative net=%s",
+			f.Start.Format(OutputDateFormat),
+			f.End.Format(OutputDateFormat),
+			f.Income,
+			f.RecurringIncome,
+			f.Expense,
+			f.RecurringExpense,
+			f.Net(),
+			MoneyWith2DecimalPlaces{cumulative},
+		)
+	}
+	return fmt.Sprintf("\nPROJECTED for next %d months (forecast, not actual data):%s",
+		len(forecast), strings.Join(lines, ""))
+}
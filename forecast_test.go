@@ -0,0 +1,131 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBuildForecast(t *testing.T) {
+	// Arrange. 3 months with monthly subscription 1000 and groceries 3000, 5000, 7000.
+	start := time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)
+	transactions := monthlyTransactions(start.AddDate(0, 0, 9), 3, 1000, "NETFLIX")
+	transactions = append(transactions,
+		newRecurringT(start.AddDate(0, 0, 2), 3000, true, "MARKET"),
+		newRecurringT(start.AddDate(0, 1, 4), 5000, true, "MARKET"),
+		newRecurringT(start.AddDate(0, 2, 20), 7000, true, "MARKET"),
+		newRecurringT(start.AddDate(0, 0, 1), 20000, false, "SALARY"),
+		newRecurringT(start.AddDate(0, 1, 1), 20000, false, "SALARY"),
+		newRecurringT(start.AddDate(0, 2, 1), 20000, false, "SALARY"),
+	)
+	factory, err := NewStatisticBuilderByDetailsSubstrings(&Config{
+		GroupNamesToSubstrings:      map[string][]string{"Subscriptions": {"NETFLIX"}, "Groceries": {"MARKET"}},
+		GroupAllUnknownTransactions: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	statistics, err := BuildMonthlyStatistic(transactions, factory, 1, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	series := DetectRecurringSeries(transactions, transactions[len(transactions)-1].Date)
+
+	// Act
+	forecast := BuildForecast(statistics, series, 2, 1, time.UTC)
+
+	// Assert
+	if len(forecast) != 2 {
+		t.Fatalf("expected 2 intervals, got %d", len(forecast))
+	}
+	expectedStart := time.Date(2023, time.April, 1, 0, 0, 0, 0, time.UTC)
+	if !forecast[0].Start.Equal(expectedStart) {
+		t.Errorf("expected start %v, got %v", expectedStart, forecast[0].Start)
+	}
+	for i, f := range forecast {
+		if f.RecurringExpense.int != 1000 {
+			t.Errorf("%d: expected recurring expense 1000, got %d", i, f.RecurringExpense.int)
+		}
+		if f.Expense.int != 6000 {
+			t.Errorf("%d: expected expense 6000, got %d", i, f.Expense.int)
+		}
+		if f.Income.int != 20000 {
+			t.Errorf("%d: expected income 20000, got %d", i, f.Income.int)
+		}
+		if f.Net().int != 14000 {
+			t.Errorf("%d: expected net 14000, got %d", i, f.Net().int)
+		}
+	}
+}
+
+func TestBuildForecast_SplitRecurring(t *testing.T) {
+	// Arrange. 3 months with monthly transfer 1000 split into rent 500 and utilities.
+	start := time.Date(2023, time.January, 5, 0, 0, 0, 0, time.UTC)
+	transactions := monthlyTransactions(start, 3, 100000, "LANDLORD")
+	factory, err := NewStatisticBuilderByDetailsSubstrings(&Config{
+		GroupAllUnknownTransactions: true,
+		SplitRules: []SplitRule{{
+			RuleConditions: RuleConditions{Substring: "LANDLORD"},
+			Parts:          []SplitPart{{Group: "Rent", Amount: &MoneyWith2DecimalPlaces{50000}}, {Group: "Utilities"}},
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	statistics, err := BuildMonthlyStatistic(transactions, factory, 1, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	series := DetectRecurringSeries(transactions, transactions[len(transactions)-1].Date)
+
+	// Act
+	forecast := BuildForecast(statistics, series, 2, 1, time.UTC)
+
+	// Assert
+	for i, f := range forecast {
+		if f.RecurringExpense.int != 100000 {
+			t.Errorf("%d: expected recurring expense 100000, got %d", i, f.RecurringExpense.int)
+		}
+		if f.Expense.int != 100000 {
+			t.Errorf("%d: expected expense 100000, got %d", i, f.Expense.int)
+		}
+	}
+}
+
+func TestBuildForecast_IgnoredRecurring(t *testing.T) {
+	// Arrange. 3 months with ignored monthly transfer to deposit and subscription.
+	start := time.Date(2023, time.January, 5, 0, 0, 0, 0, time.UTC)
+	transactions := monthlyTransactions(start, 3, 100000, "TO MY DEPOSIT")
+	transactions = append(transactions, monthlyTransactions(start.AddDate(0, 0, 5), 3, 1000, "NETFLIX")...)
+	factory, err := NewStatisticBuilderByDetailsSubstrings(&Config{
+		GroupNamesToSubstrings:      map[string][]string{"Subscriptions": {"NETFLIX"}},
+		GroupAllUnknownTransactions: true,
+		IgnoreSubstrings:            []string{"TO MY DEPOSIT"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	statistics, err := BuildMonthlyStatistic(transactions, factory, 1, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	series := DetectRecurringSeries(
+		ExcludeIgnoredTransactions(transactions, factory),
+		transactions[len(transactions)-1].Date,
+	)
+	forecast := BuildForecast(statistics, series, 2, 1, time.UTC)
+
+	// Assert
+	if len(series) != 1 || series[0].Transactions[0].Details != "NETFLIX" {
+		t.Fatalf("expected only NETFLIX series, got %+v", series)
+	}
+	for i, f := range forecast {
+		if f.RecurringExpense.int != 1000 {
+			t.Errorf("%d: expected recurring expense 1000, got %d", i, f.RecurringExpense.int)
+		}
+		if f.Expense.int != 1000 {
+			t.Errorf("%d: expected expense 1000, got %d", i, f.Expense.int)
+		}
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strings"
+	"unicode"
 )
 
 // getAbsolutePath checks if a file exists and returns its absolute path.
@@ -25,14 +27,85 @@ func getAbsolutePath(filename string) (string, error) {
 	return absPath, nil
 }
 
+// getFilesByGlob returns files matching glob. Besides `filepath.Match` syntax supports "**"
+// path element which matches any number (including zero) of nested directories.
 func getFilesByGlob(glob string) ([]string, error) {
-	files, err := filepath.Glob(glob)
+	if !strings.Contains(glob, "**") {
+		return filepath.Glob(glob)
+	}
+	if _, err := filepath.Match(strings.ReplaceAll(glob, "**", "*"), ""); err != nil {
+		return nil, err
+	}
+
+	// Walk from the deepest directory without wildcards.
+	patternElements := strings.Split(filepath.ToSlash(filepath.Clean(glob)), "/")
+	rootElements := []string{}
+	for _, element := range patternElements {
+		if strings.ContainsAny(element, "*?[\\") {
+			break
+		}
+		rootElements = append(rootElements, element)
+	}
+	patternElements = patternElements[len(rootElements):]
+	root := filepath.FromSlash(strings.Join(rootElements, "/"))
+	if len(rootElements) == 1 && rootElements[0] == "" {
+		root = string(filepath.Separator)
+	} else if root == "" {
+		root = "."
+	}
+
+	files := []string{}
+	err := filepath.WalkDir(root, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == root {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		relative, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		if matchPathElements(patternElements, strings.Split(filepath.ToSlash(relative), "/")) {
+			if root == "." {
+				path = relative
+			}
+			files = append(files, path)
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 	return files, nil
 }
 
+// matchPathElements checks that path elements match pattern elements where "**" element
+// matches any number of path elements.
+func matchPathElements(patternElements, pathElements []string) bool {
+	if len(patternElements) == 0 {
+		return len(pathElements) == 0
+	}
+	if patternElements[0] == "**" {
+		for i := 0; i <= len(pathElements); i++ {
+			if matchPathElements(patternElements[1:], pathElements[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(pathElements) == 0 {
+		return false
+	}
+	if matched, _ := filepath.Match(patternElements[0], pathElements[0]); !matched {
+		return false
+	}
+	return matchPathElements(patternElements[1:], pathElements[1:])
+}
+
 // openFileInOS opens file in OS-specific viewer.
 func openFileInOS(url string) error {
 	var err error
@@ -52,3 +125,25 @@ func openFileInOS(url string) error {
 	}
 	return nil
 }
+
+// normalizeDetails returns `Transaction.Details` without digits, punctuation and extra spaces
+// so transactions with changing order numbers, dates, etc. may be compared.
+func normalizeDetails(details string) string {
+	var sb strings.Builder
+	isSpace := true
+	for _, r := range strings.ToUpper(details) {
+		if unicode.IsLetter(r) {
+			sb.WriteRune(r)
+			isSpace = false
+		} else if !isSpace {
+			sb.WriteRune(' ')
+			isSpace = true
+		}
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// detailsTokens returns words from normalized `Transaction.Details`.
+func detailsTokens(details string) []string {
+	return strings.Fields(normalizeDetails(details))
+}
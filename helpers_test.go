@@ -0,0 +1,52 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGetFilesByGlob(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"Statement 1.xml":                "",
+		"2023/Statement 2.xml":           "",
+		"2023/alex/Statement 3.xml":      "",
+		"2023/alex/History 1.xls":        "",
+		"2024/maria/deep/Statement4.xml": "",
+	})
+	tests := []struct {
+		glob     string
+		expected []string
+	}{
+		{"*.xml", []string{"Statement 1.xml"}},
+		{"**/*.xml", []string{"2023/Statement 2.xml", "2023/alex/Statement 3.xml",
+			"2024/maria/deep/Statement4.xml", "Statement 1.xml"}},
+		{"2023/**/Statement*.xml", []string{"2023/Statement 2.xml", "2023/alex/Statement 3.xml"}},
+		{"*/alex/**", []string{"2023/alex/History 1.xls", "2023/alex/Statement 3.xml"}},
+		{"not_existing/**/*.xml", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.glob, func(t *testing.T) {
+
+			// Act
+			files, err := getFilesByGlob(filepath.Join(dir, tt.glob))
+
+			// Assert
+			if err != nil {
+				t.Fatalf("getFilesByGlob() failed: %v", err)
+			}
+			expected := []string{}
+			for _, file := range tt.expected {
+				expected = append(expected, filepath.Join(dir, file))
+			}
+			if len(files) == 0 {
+				files = []string{}
+			}
+			if !reflect.DeepEqual(files, expected) {
+				t.Errorf("getFilesByGlob() = %v, want %v", files, expected)
+			}
+		})
+	}
+}
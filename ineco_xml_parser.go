@@ -1,11 +1,11 @@
 package main
 
 import (
+	"bytes"
 	"encoding/xml"
 	"fmt"
 	"io"
 	"os"
-	"strconv"
 	"strings"
 	"time"
 
@@ -14,112 +14,269 @@ import (
 
 const InecoDateFormat = "02/01/2006"
 
-type XmlDate struct {
-	time.Time
-}
-
+// InecoTransaction is an "Operation" element of the statement. Values are kept as strings to
+// parse them after reading the whole element, so wrong values don't break reading of the file.
 type InecoTransaction struct {
-	NN                   string                  `xml:"n-n"`
-	Number               string                  `xml:"Number"`
-	Date                 XmlDate                 `xml:"Date"`
-	Currency             string                  `xml:"Currency"`
-	Income               MoneyWith2DecimalPlaces `xml:"Income"`
-	Expense              MoneyWith2DecimalPlaces `xml:"Expense"`
-	ReceiverPayerAccount string                  `xml:"Receiver-PayerAccount"`
-	ReceiverPayer        string                  `xml:"Receiver-Payer"`
-	Details              string                  `xml:"Details"`
+	XMLName              xml.Name `xml:"Operation"`
+	NN                   string   `xml:"n-n"`
+	Number               string   `xml:"Number"`
+	Date                 string   `xml:"Date"`
+	Currency             string   `xml:"Currency"`
+	Income               string   `xml:"Income"`
+	Expense              string   `xml:"Expense"`
+	ReceiverPayerAccount string   `xml:"Receiver-PayerAccount"`
+	ReceiverPayer        string   `xml:"Receiver-Payer"`
+	Details              string   `xml:"Details"`
+	// Extra are unknown elements which may mean that format of the file was changed.
+	Extra []inecoExtraElement `xml:",any"`
 }
 
-type Operations struct {
-	Transactions []InecoTransaction `xml:"Operation"`
+type inecoExtraElement struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
 }
 
 type Statement struct {
-	Client         string     `xml:"Client" validate:"required"`
-	AccountNumber  string     `xml:"AccountNumber" validate:"required"`
-	Currency       string     `xml:"Currency" validate:"required"`
-	Period         string     `xml:"Period" validate:"required"`
-	OpeningBalance string     `xml:"Openingbalance" validate:"required"`
-	ClosingBalance string     `xml:"Closingbalance" validate:"required"`
-	Operations     Operations `xml:"Operations" validate:"required"`
+	Client         string `xml:"Client" validate:"required"`
+	AccountNumber  string `xml:"AccountNumber" validate:"required"`
+	Currency       string `xml:"Currency" validate:"required"`
+	Period         string `xml:"Period" validate:"required"`
+	OpeningBalance string `xml:"Openingbalance" validate:"required"`
+	ClosingBalance string `xml:"Closingbalance" validate:"required"`
 }
 
-func (m *MoneyWith2DecimalPlaces) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
-	var v string
-	d.DecodeElement(&v, &start)
-	v = strings.Replace(v, ",", "", -1)
-	floatVal, err := strconv.ParseFloat(v, 64)
-	if err != nil {
-		return err
-	}
-	m.int = int(floatVal * 100)
-	return nil
+type InecoXmlParser struct {
 }
 
-func (xd *XmlDate) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
-	var v string
-	d.DecodeElement(&v, &start)
+// ParseRawTransactionsFromFile implements FileParser.
+func (p InecoXmlParser) ParseRawTransactionsFromFile(filePath string) ([]Transaction, error) {
+	transactions, _, err := p.ParseStatementFromFile(filePath)
+	return transactions, err
+}
 
-	parse, err := time.Parse(InecoDateFormat, v)
+// ParseStatementFromFile implements StatementFileParser.
+func (p InecoXmlParser) ParseStatementFromFile(filePath string) ([]Transaction, *AccountStatement, error) {
+	file, err := os.Open(filePath)
 	if err != nil {
-		return err
+		return nil, nil, fmt.Errorf("error opening '%s' file: %w", filePath, err)
 	}
-
-	xd.Time = parse
-	return nil
+	defer file.Close()
+	return p.ParseStatement(filePath, file)
 }
 
-type InecoXmlParser struct {
+// ParseRawTransactions implements ReaderParser.
+func (p InecoXmlParser) ParseRawTransactions(name string, reader io.Reader) ([]Transaction, error) {
+	transactions, _, err := p.ParseStatement(name, reader)
+	return transactions, err
 }
 
-// ParseRawTransactionsFromFile implements FileParser.
-func (InecoXmlParser) ParseRawTransactionsFromFile(filePath string) ([]Transaction, error) {
+// ParseStatement implements StatementReaderParser. Reads XML element by element to don't keep
+// the whole file in memory.
+func (InecoXmlParser) ParseStatement(name string, reader io.Reader) ([]Transaction, *AccountStatement, error) {
+	var stmt Statement
+	statement := &AccountStatement{Source: name}
+	transactions := []Transaction{}
+	isRootFound := false
+	validate := validator.New()
+	var diagnostics Diagnostics
+	operationNumber := 0
+	decoder := xml.NewDecoder(reader)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("error reading XML from '%s': %w", name, err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		var field *string
+		switch start.Name.Local {
+		case "Statement":
+			isRootFound = true
+			continue
+		case "Operations":
+			continue
+		case "Client":
+			field = &stmt.Client
+		case "AccountNumber":
+			field = &stmt.AccountNumber
+		case "Currency":
+			field = &stmt.Currency
+		case "Period":
+			field = &stmt.Period
+		case "Openingbalance":
+			field = &stmt.OpeningBalance
+		case "Closingbalance":
+			field = &stmt.ClosingBalance
+		case "Operation":
+			operationNumber++
+			line, _ := decoder.InputPos()
+			var operation InecoTransaction
+			if err := decoder.DecodeElement(&operation, &start); err != nil {
+				return nil, nil, fmt.Errorf("error in %d transaction in '%s': %w", operationNumber, name, err)
+			}
+			isExpense, amount, date, err := parseInecoOperation(operation, stmt.Currency, validate)
+			if err != nil {
+				raw, _ := xml.Marshal(operation)
+				diagnostics = append(diagnostics, newRowDiagnostic(name, line, string(raw),
+					"error in %d transaction: %v", operationNumber, err))
+				continue
+			}
+			if len(operation.Extra) > 0 || amount.int == 0 {
+				raw, _ := xml.Marshal(operation)
+				if len(operation.Extra) > 0 {
+					names := make([]string, len(operation.Extra))
+					for i, extra := range operation.Extra {
+						names[i] = extra.XMLName.Local
+					}
+					diagnostics = append(diagnostics, newRowWarning(name, line, string(raw),
+						"unexpected elements %v in %d transaction", names, operationNumber))
+				}
+				if amount.int == 0 {
+					diagnostics = append(diagnostics, newRowWarning(name, line, string(raw),
+						"zero amount in %d transaction", operationNumber))
+				}
+			}
+			if isExpense {
+				statement.TotalExpense.int += amount.int
+			} else {
+				statement.TotalIncome.int += amount.int
+			}
+			transactions = append(transactions, Transaction{
+				IsExpense:       isExpense,
+				Date:            date,
+				Details:         operation.Details,
+				Amount:          amount,
+				ReceiverAccount: operation.ReceiverPayerAccount,
+			})
+			continue
+		}
+		if field == nil {
+			line, _ := decoder.InputPos()
+			diagnostics = append(diagnostics, newRowWarning(name, line, "", "unexpected '%s' element", start.Name.Local))
+			if err := decoder.Skip(); err != nil {
+				return nil, nil, fmt.Errorf("error reading XML from '%s': %w", name, err)
+			}
+			continue
+		}
+		if err := decoder.DecodeElement(field, &start); err != nil {
+			return nil, nil, fmt.Errorf("error reading '%s' from '%s': %w", start.Name.Local, name, err)
+		}
+	}
+	if !isRootFound {
+		return nil, nil, fmt.Errorf("'%s' doesn't have 'Statement' element", name)
+	}
 
-	// Open XML file.
-	file, err := os.Open(filePath)
+	if err := validate.Struct(stmt); err != nil {
+		diagnostics = append(diagnostics, Diagnostic{Severity: SeverityWarning, File: name, Message: fmt.Sprintf(
+			"statement misses required elements: %v", err)})
+	}
+
+	// Set account information known only after reading the whole statement.
+	statement.Account = stmt.AccountNumber
+	statement.Currency = stmt.Currency
+	for i := range transactions {
+		transactions[i].Account = stmt.AccountNumber
+		transactions[i].Currency = stmt.Currency
+	}
+
+	// Parse balances and period of the statement.
+	var err error
+	statement.OpeningBalance, err = parseMoney(stmt.OpeningBalance, stmt.Currency)
 	if err != nil {
-		return nil, fmt.Errorf("error opening '%s' file: %w", filePath, err)
+		return nil, nil, fmt.Errorf("error parsing opening balance '%s': %w", stmt.OpeningBalance, err)
 	}
-	defer file.Close()
+	statement.ClosingBalance, err = parseMoney(stmt.ClosingBalance, stmt.Currency)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error parsing closing balance '%s': %w", stmt.ClosingBalance, err)
+	}
+	statement.Start, statement.End, err = parseInecoPeriod(stmt.Period)
+	if err != nil {
+		// Fallback to dates of transactions.
+		for i, t := range transactions {
+			if i == 0 || t.Date.Before(statement.Start) {
+				statement.Start = t.Date
+			}
+			if i == 0 || t.Date.After(statement.End) {
+				statement.End = t.Date
+			}
+		}
+	}
+	if len(diagnostics) > 0 {
+		return transactions, statement, diagnostics
+	}
+	return transactions, statement, nil
+}
 
-	// Read the file content
-	xmlData, err := io.ReadAll(file)
+// parseInecoOperation returns direction, amount and date of the operation. Amounts are parsed
+// with precision of the operation currency or of the statement currency if operation doesn't have it.
+func parseInecoOperation(
+	operation InecoTransaction,
+	statementCurrency string,
+	validate *validator.Validate,
+) (bool, MoneyWith2DecimalPlaces, time.Time, error) {
+	var income, expense MoneyWith2DecimalPlaces
+	if err := validate.Struct(operation); err != nil {
+		return false, income, time.Time{}, err
+	}
+	date, err := time.Parse(InecoDateFormat, strings.TrimSpace(operation.Date))
+	if err != nil {
+		return false, income, time.Time{}, fmt.Errorf("wrong date: %w", err)
+	}
+	currency := operation.Currency
+	if currency == "" {
+		currency = statementCurrency
+	}
+	income, err = parseMoney(operation.Income, currency)
 	if err != nil {
-		return nil, fmt.Errorf("error reading file: %v", err)
+		return false, income, time.Time{}, fmt.Errorf("wrong income: %w", err)
 	}
+	expense, err = parseMoney(operation.Expense, currency)
+	if err != nil {
+		return false, income, time.Time{}, fmt.Errorf("wrong expense: %w", err)
+	}
+	if income.int <= 0 {
+		return true, expense, date, nil
+	}
+	return false, income, date, nil
+}
 
-	// Unmarshal XML.
-	var stmt Statement
-	err = xml.Unmarshal(xmlData, &stmt)
+// parseInecoPeriod parses "Period" field of Inecobank statement like "01/08/2023 - 31/08/2023".
+func parseInecoPeriod(period string) (time.Time, time.Time, error) {
+	parts := strings.Split(period, "-")
+	if len(parts) != 2 {
+		return time.Time{}, time.Time{}, fmt.Errorf("unexpected period format '%s'", period)
+	}
+	start, err := time.Parse(InecoDateFormat, strings.TrimSpace(parts[0]))
 	if err != nil {
-		return nil, fmt.Errorf("error unmarshalling XML: %v", err)
+		return time.Time{}, time.Time{}, err
 	}
+	end, err := time.Parse(InecoDateFormat, strings.TrimSpace(parts[1]))
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return start, end, nil
+}
 
-	// Validate that all fields are set.
-	validate := validator.New()
-	for i, operation := range stmt.Operations.Transactions {
-		err = validate.Struct(operation)
+// sniffInecoXml checks that file is XML with "Statement" root element.
+func sniffInecoXml(head []byte, _ func() ([]byte, error)) bool {
+	decoder := xml.NewDecoder(bytes.NewReader(head))
+	for {
+		token, err := decoder.Token()
 		if err != nil {
-			return nil, fmt.Errorf("error in %d transaction in '%s': %v", i+1, filePath, err)
+			return false
 		}
-	}
-
-	// Conver Inecobank rows to unified transactions.
-	transactions := make([]Transaction, 0, len(stmt.Operations.Transactions))
-	for _, t := range stmt.Operations.Transactions {
-		isExpense := t.Income.int <= 0
-		amount := t.Income.int
-		if isExpense {
-			amount = t.Expense.int
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Local == "Statement"
 		}
-		transactions = append(transactions, Transaction{
-			IsExpense: isExpense,
-			Date:      t.Date.Time,
-			Details:   t.Details,
-			Amount:    MoneyWith2DecimalPlaces{amount},
-		})
-	}
-	return transactions, nil
+	}
 }
 
 var _ FileParser = InecoXmlParser{}
+var _ StatementFileParser = InecoXmlParser{}
+var _ ReaderParser = InecoXmlParser{}
+var _ StatementReaderParser = InecoXmlParser{}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const inecoValidStatement = `<?xml version="1.0" encoding="utf-8"?>
+<Statement>
+  <Client>JOHN DOE</Client>
+  <AccountNumber>2050000000000000</AccountNumber>
+  <Currency>AMD</Currency>
+  <Period>01/08/2023 - 31/08/2023</Period>
+  <Openingbalance>10,000.00</Openingbalance>
+  <Closingbalance>1,009,500.50</Closingbalance>
+  <Operations>
+    <Operation>
+      <n-n>1</n-n>
+      <Number>111</Number>
+      <Date>01/08/2023</Date>
+      <Currency>AMD</Currency>
+      <Income>1,000,000.00</Income>
+      <Expense>0.00</Expense>
+      <Receiver-PayerAccount>1930000000000000</Receiver-PayerAccount>
+      <Receiver-Payer>EMPLOYER LLC</Receiver-Payer>
+      <Details>ԱՄՍՎԱ ԱՇԽԱՏԱՎԱՐՁ</Details>
+    </Operation>
+    <Operation>
+      <n-n>2</n-n>
+      <Number>112</Number>
+      <Date>05/08/2023</Date>
+      <Currency>AMD</Currency>
+      <Income>0.00</Income>
+      <Expense>499.50</Expense>
+      <Receiver-PayerAccount>2050000000000000</Receiver-PayerAccount>
+      <Receiver-Payer>JOHN DOE</Receiver-Payer>
+      <Details>YANDEX.GO, YEREVAN</Details>
+    </Operation>
+  </Operations>
+</Statement>
+`
+
+const inecoWrongBalanceStatement = `<?xml version="1.0" encoding="utf-8"?>
+<Statement>
+  <Client>JOHN DOE</Client>
+  <AccountNumber>2050000000000000</AccountNumber>
+  <Currency>AMD</Currency>
+  <Period>01/09/2023 - 30/09/2023</Period>
+  <Openingbalance>1,009,500.50</Openingbalance>
+  <Closingbalance>1,000,000.00</Closingbalance>
+  <Operations>
+    <Operation>
+      <n-n>1</n-n>
+      <Number>113</Number>
+      <Date>02/09/2023</Date>
+      <Currency>AMD</Currency>
+      <Income>0.00</Income>
+      <Expense>1,000.00</Expense>
+      <Receiver-PayerAccount>2050000000000000</Receiver-PayerAccount>
+      <Receiver-Payer>JOHN DOE</Receiver-Payer>
+      <Details>SAS SUPERMARKET</Details>
+    </Operation>
+  </Operations>
+</Statement>
+`
+
+func TestInecoXmlParser_ParseStatement(t *testing.T) {
+	// Arrange
+	name := "valid_statement.xml"
+
+	// Act
+	transactions, statement, err := InecoXmlParser{}.ParseStatement(name, strings.NewReader(inecoValidStatement))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("ParseStatement() failed: %v", err)
+	}
+	expectedTransactions := []Transaction{
+		{
+			IsExpense:       false,
+			Date:            time.Date(2023, time.August, 1, 0, 0, 0, 0, time.UTC),
+			Details:         "ԱՄՍՎԱ ԱՇԽԱՏԱՎԱՐՁ",
+			Amount:          MoneyWith2DecimalPlaces{10000000000},
+			Account:         "2050000000000000",
+			Currency:        "AMD",
+			ReceiverAccount: "1930000000000000",
+		},
+		{
+			IsExpense:       true,
+			Date:            time.Date(2023, time.August, 5, 0, 0, 0, 0, time.UTC),
+			Details:         "YANDEX.GO, YEREVAN",
+			Amount:          MoneyWith2DecimalPlaces{4995000},
+			Account:         "2050000000000000",
+			Currency:        "AMD",
+			ReceiverAccount: "2050000000000000",
+		},
+	}
+	if !reflect.DeepEqual(transactions, expectedTransactions) {
+		t.Errorf("ParseStatement() = %v, want %v", transactions, expectedTransactions)
+	}
+	expectedStatement := &AccountStatement{
+		Source:         name,
+		Account:        "2050000000000000",
+		Currency:       "AMD",
+		Start:          time.Date(2023, time.August, 1, 0, 0, 0, 0, time.UTC),
+		End:            time.Date(2023, time.August, 31, 0, 0, 0, 0, time.UTC),
+		OpeningBalance: MoneyWith2DecimalPlaces{100000000},
+		ClosingBalance: MoneyWith2DecimalPlaces{10095005000},
+		TotalIncome:    MoneyWith2DecimalPlaces{10000000000},
+		TotalExpense:   MoneyWith2DecimalPlaces{4995000},
+	}
+	if !reflect.DeepEqual(statement, expectedStatement) {
+		t.Errorf("ParseStatement() statement = %+v, want %+v", statement, expectedStatement)
+	}
+}
+
+func TestInecoXmlParser_ParseStatementErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"not_xml", "Date,Amount\n", "doesn't have 'Statement' element"},
+		{"broken_xml", "<Statement><Operations><Operation>", "unexpected EOF"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// Act
+			_, _, err := InecoXmlParser{}.ParseStatement(tt.name, strings.NewReader(tt.content))
+
+			// Assert
+			if err == nil {
+				t.Fatal("Expected error, but got no error")
+			}
+			checkErrorContainsSubstring(t, err, tt.expected)
+		})
+	}
+}
+
+func TestInecoXmlParser_ParseStatementDiagnostics(t *testing.T) {
+	// Arrange
+	content := strings.Replace(inecoValidStatement, "<Expense>499.50</Expense>", "<Expense>abc</Expense>", 1)
+
+	// Act
+	transactions, statement, err := InecoXmlParser{}.ParseStatement("wrong_amount.xml", strings.NewReader(content))
+
+	// Assert
+	var diagnostics Diagnostics
+	if !errors.As(err, &diagnostics) {
+		t.Fatalf("Expected diagnostics, got %v", err)
+	}
+	if len(diagnostics) != 1 {
+		t.Fatalf("Expected 1 diagnostic, got %v", diagnostics)
+	}
+	diagnostic := diagnostics[0]
+	if diagnostic.Severity != SeverityError || diagnostic.File != "wrong_amount.xml" || diagnostic.Line != 21 {
+		t.Errorf("Unexpected diagnostic %+v", diagnostic)
+	}
+	checkErrorContainsSubstring(t, err, "error in 2 transaction: wrong expense")
+	if !strings.Contains(diagnostic.Raw, "<Expense>abc</Expense>") {
+		t.Errorf("Expected raw operation in diagnostic, got %s", diagnostic.Raw)
+	}
+	if len(transactions) != 1 || statement == nil || statement.TotalIncome.int != 10000000000 {
+		t.Errorf("Expected the first transaction to be parsed, got %v and %+v", transactions, statement)
+	}
+}
+
+func TestInecoXmlParser_ParseStatementWarnings(t *testing.T) {
+	// Arrange
+	content := strings.Replace(inecoValidStatement, "<Expense>499.50</Expense>",
+		"<Expense>0.00</Expense><Fee>1.00</Fee>", 1)
+	content = strings.Replace(content, "<Client>JOHN DOE</Client>", "<Address>YEREVAN</Address>", 1)
+
+	// Act
+	transactions, _, err := InecoXmlParser{}.ParseStatement("changed.xml", strings.NewReader(content))
+
+	// Assert
+	var diagnostics Diagnostics
+	if !errors.As(err, &diagnostics) {
+		t.Fatalf("Expected diagnostics, got %v", err)
+	}
+	expected := []string{
+		"unexpected 'Address' element",
+		"unexpected elements [Fee] in 2 transaction",
+		"zero amount in 2 transaction",
+		"statement misses required elements",
+	}
+	if len(diagnostics) != len(expected) {
+		t.Fatalf("Expected %d diagnostics, got %v", len(expected), diagnostics)
+	}
+	for i, message := range expected {
+		if diagnostics[i].Severity != SeverityWarning || !strings.Contains(diagnostics[i].Message, message) {
+			t.Errorf("Expected warning about '%s', got %+v", message, diagnostics[i])
+		}
+	}
+	if len(transactions) != 2 {
+		t.Errorf("Expected all transactions to be parsed, got %v", transactions)
+	}
+}
+
+func TestBalances(t *testing.T) {
+	// Arrange
+	statements := []AccountStatement{}
+	transactions := []Transaction{}
+	for i, content := range []string{inecoValidStatement, inecoWrongBalanceStatement} {
+		parsed, statement, err := InecoXmlParser{}.ParseStatement(fmt.Sprintf("%d.xml", i), strings.NewReader(content))
+		if err != nil {
+			t.Fatalf("ParseStatement() failed: %v", err)
+		}
+		transactions = append(transactions, parsed...)
+		statements = append(statements, *statement)
+	}
+
+	// Act
+	warnings := VerifyStatements(statements)
+	ComputeRunningBalances(transactions, statements)
+	statistics := []*IntervalStatistic{
+		{End: time.Date(2023, time.August, 31, 23, 59, 59, 0, time.UTC)},
+		{End: time.Date(2023, time.September, 30, 23, 59, 59, 0, time.UTC)},
+	}
+	AssignIntervalBalances(statistics, transactions, statements)
+
+	// Assert
+	if len(warnings) != 1 {
+		t.Errorf("Expected 1 warning about wrong_balance.xml, got %v", warnings)
+	}
+	expectedBalances := []int{10100000000, 10095005000, 10085005000}
+	for i, expected := range expectedBalances {
+		if transactions[i].Balance == nil || transactions[i].Balance.int != expected {
+			t.Errorf("Expected balance %d after %d transaction, got %v", expected, i, transactions[i].Balance)
+		}
+	}
+	if balance := statistics[0].Balances["2050000000000000"]; balance.int != 10095005000 {
+		t.Errorf("Expected balance 10095005000 at the end of August, got %d", balance.int)
+	}
+	if balance := statistics[1].Balances["2050000000000000"]; balance.int != 10085005000 {
+		t.Errorf("Expected balance 10085005000 at the end of September, got %d", balance.int)
+	}
+}
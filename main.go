@@ -1,9 +1,15 @@
 package main
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
 	"strings"
 	"time"
 
@@ -13,17 +19,48 @@ import (
 type Args struct {
 	ConfigPath   string `arg:"positional" help:"Path to the configuration YAML file. By default is used 'config.yaml' path."`
 	DontOpenFile bool   `arg:"-n" help:"Flag to don't open result file in OS at the end, only print in STDOUT."`
+	Categorize   bool   `arg:"--categorize" help:"Walk through 'unknown' transactions, choose groups for them and save rules into the configuration file."`
+	SuggestRules bool   `arg:"--suggest-rules" help:"Print rules for similar 'unknown' transactions in YAML ready to paste into 'groupNamesToSubstrings'."`
+	Explain      string `arg:"--explain" help:"Explain how transactions are handled. Transactions are searched by date and amount like '2023-08-05 499.50' or by substring in details."`
+	Scan         string `arg:"--scan" help:"Directory to search files of all supported formats in (including nested directories) instead of 'sources' from the configuration file."`
+	CheckConfig  bool   `arg:"--check-config" help:"Check configuration for globs without files, shadowed and unused rules, etc. Exits with non-zero code if there are errors."`
+	Json         bool   `arg:"--json" help:"Print result of '--check-config' in JSON."`
+	Diagnostics  string `arg:"--diagnostics" help:"Path to the file to write problems with files and rows into in JSON. Use '-' for STDOUT."`
+	Strict       bool   `arg:"--strict" help:"Exit with non-zero code and list of problems if any file or row has problems: skipped rows, wrong or zero amounts, duplicates, extra columns, missing headers, etc."`
+	From         string `arg:"--from" help:"Aggregate only transactions made since this date in YYYY-MM-DD format. Overrides 'from' and 'lastMonths' settings."`
+	To           string `arg:"--to" help:"Aggregate only transactions made till this date (inclusive) in YYYY-MM-DD format. Overrides 'to' setting."`
+	LastMonths   uint   `arg:"--last-months" help:"Aggregate only transactions of this number of last months including the current one. Overrides 'from' and 'lastMonths' settings."`
+	Store        string `arg:"--store" help:"Path to the JSON-lines file to import transactions into and build report from. Overrides 'store' setting."`
 }
 
 type FileParser interface {
 	ParseRawTransactionsFromFile(filePath string) ([]Transaction, error)
 }
 
+// StatementFileParser is a [main.FileParser] which also knows balances of the account from the file.
+type StatementFileParser interface {
+	ParseStatementFromFile(filePath string) ([]Transaction, *AccountStatement, error)
+}
+
+// ReaderParser is a [main.FileParser] which may parse transactions from any reader, for example
+// from STDIN, archive or memory. The name is used in messages and as a source of statement.
+type ReaderParser interface {
+	ParseRawTransactions(name string, reader io.Reader) ([]Transaction, error)
+}
+
+// StatementReaderParser is a [main.ReaderParser] which also knows balances of the account.
+type StatementReaderParser interface {
+	ParseStatement(name string, reader io.Reader) ([]Transaction, *AccountStatement, error)
+}
+
 // Version is application version string and should be updated with `go build -ldflags`.
 var Version = "development"
 
 const resultFilePath = "Bank Aggregated Statement.txt"
 
+// stdinFileName is a special glob of the source to read file from STDIN.
+const stdinFileName = "-"
+
 func main() {
 	log.Printf("Version: %s", Version)
 	configPath := "config.yaml"
@@ -49,6 +86,18 @@ func main() {
 		)
 	}
 
+	setCurrencyPrecisions(config.CurrencyPrecisions)
+
+	// Replace sources with all files from the directory if requested.
+	if args.Scan != "" {
+		config.Sources = []Source{{
+			Type:             SourceTypeAuto,
+			Globs:            []string{filepath.Join(args.Scan, "**")},
+			MyAccounts:       config.MyAmeriaMyAccounts,
+			IncomeSubstrings: config.MyAmeriaIncomeSubstrings,
+		}}
+	}
+
 	// Parse timezone or set system.
 	timeZone, err := time.LoadLocation(config.TimeZoneLocation)
 	if err != nil {
@@ -58,12 +107,39 @@ func main() {
 		)
 	}
 
-	// Build groupsExtractor earlier to check for configuration errors.
-	groupExtractorFactory, err := NewStatisticBuilderByDetailsSubstrings(
-		config.GroupNamesToSubstrings,
-		config.GroupAllUnknownTransactions,
-		config.IgnoreSubstrings,
+	// Restrict dates of transactions. Flags override settings.
+	if args.From != "" || args.LastMonths > 0 {
+		config.From, config.LastMonths = args.From, args.LastMonths
+	}
+	if args.To != "" {
+		config.To = args.To
+	}
+	dateRange, err := NewDateRange(
+		config.From,
+		config.To,
+		config.LastMonths,
+		config.MonthStartDayNumber,
+		time.Now(),
+		timeZone,
 	)
+	if err != nil {
+		fatalError(fmt.Sprintf("Wrong dates range: %v\n", err), isOpenFileWithResult)
+	}
+
+	// Open store of previously imported transactions if need.
+	if args.Store != "" {
+		config.Store = args.Store
+	}
+	var store *TransactionStore
+	if config.Store != "" {
+		store, err = OpenTransactionStore(config.Store)
+		if err != nil {
+			fatalError(fmt.Sprintf("Can't read store '%s': %v\n", config.Store, err), isOpenFileWithResult)
+		}
+	}
+
+	// Build groupsExtractor earlier to check for configuration errors.
+	groupExtractorFactory, err := NewStatisticBuilderByDetailsSubstrings(config)
 	if err != nil {
 		fatalError(fmt.Sprintf("Can't create statistic builder: %#v", err), isOpenFileWithResult)
 	}
@@ -71,48 +147,92 @@ func main() {
 	// Log settings.
 	log.Printf("Using configuration: %+v", config)
 
+	// Check configuration if requested. Works even if there are no transactions.
+	if args.CheckConfig {
+		transactions, _, _, err := parseAllTransactions(config, store, false)
+		if err != nil {
+			log.Printf("Only rules are checked because transactions are not available: %v", err)
+		}
+		setOutputPrecision(transactions, nil)
+		issues, err := CheckConfig(config, groupExtractorFactory, transactions)
+		if err != nil {
+			log.Fatalf("Can't check configuration: %v", err)
+		}
+		output, err := ConfigIssuesToString(issues, args.Json)
+		if err != nil {
+			log.Fatalf("Can't print configuration issues: %v", err)
+		}
+		fmt.Print(output)
+		if HasConfigErrors(issues) {
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Parse files to raw transactions.
-	parsingWarnings := []string{}
-	transactions, warning, err := parseTransactionFiles(
-		config.InecobankStatementFilesGlob,
-		InecoXmlParser{},
-	)
+	transactions, statements, diagnostics, err := parseAllTransactions(config, store, true)
 	if err != nil {
-		fatalError(fmt.Sprintf("Can't parse Inecobank statements: %#v", err), isOpenFileWithResult)
+		fatalError(err.Error(), isOpenFileWithResult)
 	}
-	if warning != "" {
-		parsingWarnings = append(parsingWarnings, "Inecobank statements parsing warning: "+warning)
+	log.Printf("Total found %d transactions.", len(transactions))
+	setOutputPrecision(transactions, statements)
+	if refunds := DetectRefunds(transactions, config.Refunds, groupExtractorFactory); refunds > 0 {
+		log.Printf("Detected %d refunds.", refunds)
 	}
-	myAmeriaTransactions, warning, err := parseTransactionFiles(
-		config.MyAmeriaHistoryFilesGlob,
-		MyAmeriaExcelFileParser{
-			MyAccounts:              config.MyAmeriaMyAccounts,
-			DetailsIncomeSubstrings: config.MyAmeriaIncomeSubstrings,
-		},
-	)
-	if err != nil {
-		fatalError(fmt.Sprintf("Can't parse MyAmeria History: %#v", err), isOpenFileWithResult)
+	diagnostics = append(diagnostics, AllocateCashSpending(transactions, config.CashWithdrawalSubstrings)...)
+
+	// Calculate running balances and restrict dates only after it because balances depend on all transactions.
+	ComputeRunningBalances(transactions, statements)
+	allTransactions := transactions
+	if dateRange.IsSet() {
+		transactions = dateRange.Filter(allTransactions)
+		log.Printf("Using %d transactions from %s dates range.", len(transactions), dateRange)
+		if len(transactions) == 0 {
+			fatalError(fmt.Sprintf("There are no transactions in %s dates range.\n", dateRange), isOpenFileWithResult)
+		}
+	}
+
+	// Run interactive categorization if requested.
+	if args.Categorize {
+		if err := runCategorization(configPath, config, transactions, timeZone, os.Stdin, os.Stdout); err != nil {
+			log.Fatalf("Categorization failed: %v", err)
+		}
+		return
 	}
-	if warning != "" {
-		parsingWarnings = append(parsingWarnings, "MyAmeria History parsing warning: "+warning)
+
+	// Print suggested rules for "unknown" transactions if requested.
+	if args.SuggestRules {
+		statistics, err := buildStatisticsWithCommonUnknownGroup(config, transactions, timeZone)
+		if err != nil {
+			log.Fatalf("Can't build statistic: %v", err)
+		}
+		fmt.Print(RuleSuggestionsToYaml(SuggestRules(collectUnknownTransactions(statistics))))
+		return
 	}
-	transactions = append(transactions, myAmeriaTransactions...)
-	ameriaCsvTransactions, warning, err := parseTransactionFiles(
-		config.AmeriaCsvFilesGlob,
-		AmeriaCsvFileParser{},
-	)
-	if err != nil {
-		fatalError(fmt.Sprintf("Can't parse Ameria in-CSV transactions: %#v", err), isOpenFileWithResult)
+
+	// Print explanation for the requested transactions.
+	if args.Explain != "" {
+		explanation, err := ExplainTransactions(args.Explain, transactions, groupExtractorFactory, config, timeZone)
+		if err != nil {
+			log.Fatalf("Can't explain transactions: %v", err)
+		}
+		fmt.Print(explanation)
+		return
 	}
-	if warning != "" {
-		parsingWarnings = append(parsingWarnings, "Ameria in-CSV transactions parsing warning: "+warning)
+
+	// Check balances from statements.
+	diagnostics = append(diagnostics, VerifyStatements(statements)...)
+	if args.Diagnostics != "" {
+		if err := writeDiagnostics(args.Diagnostics, diagnostics); err != nil {
+			log.Fatalf("Can't write diagnostics: %v", err)
+		}
 	}
-	transactions = append(transactions, ameriaCsvTransactions...)
-	if len(transactions) < 1 {
-		fatalError(fmt.Sprintf("Can't find transactions, check that '%s' or '%s' matches something",
-			config.InecobankStatementFilesGlob, config.MyAmeriaHistoryFilesGlob), isOpenFileWithResult)
+	if args.Strict && len(diagnostics) > 0 {
+		fatalError(
+			fmt.Sprintf("Strict mode: found %d problem(s) in files.\n%s", len(diagnostics), DiagnosticsToString(diagnostics)),
+			isOpenFileWithResult,
+		)
 	}
-	log.Printf("Total found %d transactions.", len(transactions))
 
 	// Build statistic.
 	statistics, err := BuildMonthlyStatistic(
@@ -124,12 +244,20 @@ func main() {
 	if err != nil {
 		fatalError(fmt.Sprintf("Can't build statistic: %#v", err), isOpenFileWithResult)
 	}
+	if config.ClassifyUnknownTransactions {
+		classifier := TrainClassifier(statistics)
+		ApplyClassifier(statistics, classifier, config.ClassifierMinConfidence, config.GroupAllUnknownTransactions)
+	}
+	AssignIntervalBalances(statistics, allTransactions, statements)
 
 	// Process received statistics.
-	result := strings.Join(parsingWarnings, "\n")
+	result := DiagnosticsToString(diagnostics)
 	for _, s := range statistics {
 		if config.DetailedOutput {
 			result = result + "\n" + s.String()
+			if config.OutputByAccounts {
+				result = result + AccountsBreakdownToString(s)
+			}
 			continue
 		}
 
@@ -137,7 +265,7 @@ func main() {
 		income := MapOfGroupsToString(s.Income)
 		expense := MapOfGroupsToString(s.Expense)
 		result = result + "\n" + fmt.Sprintf(
-			"\n%s..%s:\n  Income (%d, sum=%s):%s\n  Expenses (%d, sum=%s):%s",
+			"\n%s..%s:\n  Income (%d, sum=%s):%s\n  Expenses (%d, sum=%s):%s%s%s",
 			s.Start.Format(OutputDateFormat),
 			s.End.Format(OutputDateFormat),
 			len(income),
@@ -146,10 +274,36 @@ func main() {
 			len(s.Expense),
 			MapOfGroupsSum(s.Expense),
 			strings.Join(expense, ""),
+			IgnoredToString(s, false),
+			BalancesToString(s.Balances),
 		)
+		if config.OutputByAccounts {
+			result = result + AccountsBreakdownToString(s)
+		}
 	}
 	result = fmt.Sprintf("%s\nTotal %d months.", result, len(statistics))
 
+	// Find recurring transactions and build forecast if need.
+	if config.DetectRecurring || config.ForecastMonths > 0 {
+		// Recurring transactions need long history so they are searched in all transactions till the end of range.
+		// Ignored transactions aren't counted in the statistic so they can't recur in it either.
+		history := ExcludeIgnoredTransactions(DateRange{To: dateRange.To}.Filter(allTransactions), groupExtractorFactory)
+		series := DetectRecurringSeries(history, transactions[len(transactions)-1].Date)
+		if config.DetectRecurring {
+			result = result + "\n" + RecurringSeriesToString(series)
+		}
+		if config.ForecastMonths > 0 {
+			forecast := BuildForecast(
+				statistics,
+				series,
+				config.ForecastMonths,
+				config.MonthStartDayNumber,
+				timeZone,
+			)
+			result = result + "\n" + ForecastToString(forecast)
+		}
+	}
+
 	// Always print result into logs and conditionally into the file which open through the OS.
 	log.Print(result)
 	if !args.DontOpenFile { // Twice no here, but we need in good default value for the flag and too lazy.
@@ -157,6 +311,255 @@ func main() {
 	}
 }
 
+// parseAllTransactions parses transactions from all sources specified in configuration.
+// If `store` is provided then files which are not in it yet are imported into it, already imported
+// ones are skipped, and transactions from the store are returned together with not imported ones.
+// If `isImport` is false then the store is only read and new transactions are not written into it.
+// Returns list of transactions, statements with balances, problems with files and rows, and fatal error.
+func parseAllTransactions(
+	config *Config,
+	store *TransactionStore,
+	isImport bool,
+) ([]Transaction, []AccountStatement, []Diagnostic, error) {
+	if len(config.Sources) == 0 && store == nil {
+		return nil, nil, nil, fmt.Errorf("Configuration doesn't have 'sources', specify them or use '--scan' flag")
+	}
+	diagnostics := []Diagnostic{}
+	jobs := []parseJob{}
+	globs := []string{}
+	filesByHash := map[string]string{}
+	for i, source := range config.Sources {
+		files, err := getSourceFiles(source)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("Can't find files of %s source: %#v", source.Type, err)
+		}
+		sourceFiles, fileDiagnostics := expandSourceFiles(files)
+		diagnostics = append(diagnostics, fileDiagnostics...)
+		filesByType, fileDiagnostics, err := groupSourceFilesByType(source, sourceFiles)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("Source #%d is wrong: %w", i+1, err)
+		}
+		diagnostics = append(diagnostics, fileDiagnostics...)
+		for _, parserType := range fileParserTypes {
+			parser := parserType.NewParser(source)
+			for _, file := range filesByType[parserType.Name] {
+				job := parseJob{file: file, parser: parser, source: source}
+				// STDIN is not stored because it can't be read twice, manual files because user edits them.
+				if store != nil && file.Name != stdinFileName && parserType.Name != SourceTypeManual {
+					hash, err := hashSourceFile(file)
+					if err != nil {
+						diagnostics = append(diagnostics, Diagnostic{Severity: SeverityError, File: file.Name, Message: fmt.Sprintf(
+							"Can't read file: %v", err)})
+						continue
+					}
+					if stored, ok := store.ImportedFile(hash); ok {
+						log.Printf("'%s' is already imported into store as '%s' at %s, skipped it.",
+							file.Name, stored.Name, stored.ImportedAt.Format(OutputDateFormat))
+						continue
+					}
+					if name, ok := filesByHash[hash]; ok {
+						log.Printf("'%s' is the same as '%s', skipped it.", file.Name, name)
+						continue
+					}
+					filesByHash[hash] = file.Name
+					job.hash = hash
+				}
+				jobs = append(jobs, job)
+			}
+		}
+		globs = append(globs, source.Globs...)
+	}
+
+	// Parse files in parallel, each file is independent.
+	results := runParseJobs(jobs, runtime.GOMAXPROCS(0), func(done int, job parseJob, result parseResult) {
+		log.Printf("[%d/%d] Found %d transactions in '%s' file.",
+			done, len(jobs), len(result.transactions), job.file.Name)
+	})
+	transactions := []Transaction{}
+	statements := []AccountStatement{}
+	filesByTransaction := map[transactionKey]string{}
+	for i, result := range results {
+		job := jobs[i]
+		parserName := strings.TrimPrefix(fmt.Sprintf("%T", job.parser), "main.")
+		var rowDiagnostics Diagnostics
+		isParsedWithoutErrors := result.err == nil
+		if errors.As(result.err, &rowDiagnostics) {
+			diagnostics = append(diagnostics, rowDiagnostics...)
+			isParsedWithoutErrors = !hasErrorDiagnostics(rowDiagnostics)
+		} else if result.err != nil {
+			diagnostics = append(diagnostics, Diagnostic{Severity: SeverityError, File: job.file.Name, Message: fmt.Sprintf(
+				"%s can't parse transactions: %v", parserName, result.err)})
+		}
+		if result.err == nil && len(result.transactions) < 1 {
+			diagnostics = append(diagnostics, Diagnostic{Severity: SeverityWarning, File: job.file.Name, Message: fmt.Sprintf(
+				"%s can't find transactions.", parserName)})
+		}
+		sourceStatements := []AccountStatement{}
+		if result.statement != nil {
+			sourceStatements = append(sourceStatements, *result.statement)
+		}
+		source := fmt.Sprintf("'%s' by %s", job.file.Name, parserName)
+		for i := range result.transactions {
+			result.transactions[i].Source = source
+		}
+		applySourceSettings(job.source, result.transactions, sourceStatements)
+		for _, t := range result.transactions {
+			key := newTransactionKey(t)
+			file, ok := filesByTransaction[key]
+			if !ok {
+				filesByTransaction[key] = job.file.Name
+			} else if file != job.file.Name {
+				diagnostics = append(diagnostics, Diagnostic{
+					Severity: SeverityWarning,
+					File:     job.file.Name,
+					Raw:      t.String(),
+					Message:  fmt.Sprintf("Duplicate of transaction from '%s'.", file),
+				})
+			}
+		}
+
+		// Import files without errors into store, others are parsed again next time.
+		if job.hash != "" && isParsedWithoutErrors && len(result.transactions) > 0 {
+			if !isImport {
+				newTransactions, newStatements := store.filterNew(result.transactions, sourceStatements)
+				transactions = append(transactions, newTransactions...)
+				statements = append(statements, newStatements...)
+				continue
+			}
+			stored, err := store.Import(job.file.Name, job.hash, result.transactions, sourceStatements)
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("Can't import '%s' into store: %w", job.file.Name, err)
+			}
+			log.Printf("Imported %d new of %d transactions from '%s' into store.",
+				stored.Transactions, len(result.transactions), job.file.Name)
+			continue
+		}
+		transactions = append(transactions, result.transactions...)
+		statements = append(statements, sourceStatements...)
+	}
+	if store != nil {
+		storedTransactions := store.Transactions()
+		log.Printf("Store has %d transactions from %d files.", len(storedTransactions), store.FilesCount())
+		transactions = append(storedTransactions, transactions...)
+		statements = append(store.Statements(), statements...)
+	}
+	if len(transactions) < 1 {
+		return nil, nil, nil, fmt.Errorf("Can't find transactions, check that '%s' matches something",
+			strings.Join(globs, "' or '"))
+	}
+	return transactions, statements, diagnostics, nil
+}
+
+// expandSourceFiles replaces containers (archives, emails) by files inside them.
+// Returns diagnostics about containers which can't be read.
+func expandSourceFiles(files []string) ([]sourceFile, []Diagnostic) {
+	result := []sourceFile{}
+	diagnostics := []Diagnostic{}
+	for _, file := range files {
+		if !isContainerFile(file) {
+			result = append(result, sourceFile{Name: file})
+			continue
+		}
+		data, err := os.ReadFile(file)
+		if err == nil {
+			var entries []sourceFile
+			entries, err = readContainerEntries(file, data)
+			result = append(result, entries...)
+			log.Printf("Found %d files in '%s'.", len(entries), file)
+		}
+		if err != nil {
+			diagnostics = append(diagnostics, Diagnostic{Severity: SeverityError, File: file, Message: fmt.Sprintf(
+				"Can't read files from container: %v", err)})
+		}
+	}
+	return result, diagnostics
+}
+
+// groupSourceFilesByType returns files of the source by names of their types.
+// Detects type of each file for `SourceTypeAuto` sources and of files from containers for all sources.
+// Returns diagnostics about unrecognized files. Unrecognized files from containers are only logged
+// because emails usually have a lot of not related attachments.
+func groupSourceFilesByType(source Source, files []sourceFile) (map[string][]sourceFile, []Diagnostic, error) {
+	if source.Type != SourceTypeAuto {
+		if _, err := findFileParserType(source.Type); err != nil {
+			return nil, nil, err
+		}
+	}
+	filesByType := map[string][]sourceFile{}
+	diagnostics := []Diagnostic{}
+	for _, file := range files {
+		if source.Type != SourceTypeAuto && !file.IsEntry {
+			filesByType[source.Type] = append(filesByType[source.Type], file)
+			continue
+		}
+		parserType, err := detectFileParserType(file)
+		if err != nil {
+			diagnostics = append(diagnostics, Diagnostic{Severity: SeverityError, File: file.Name, Message: fmt.Sprintf(
+				"Can't read file to detect its format: %v", err)})
+			continue
+		}
+		if parserType == nil || (source.Type != SourceTypeAuto && parserType.Name != source.Type) {
+			message := fmt.Sprintf("Unrecognized format of '%s' file, skipped it.", file.Name)
+			if parserType != nil {
+				message = fmt.Sprintf("'%s' file has %s format, skipped it.", file.Name, parserType.Name)
+			}
+			if file.IsEntry {
+				log.Println(message)
+			} else {
+				diagnostics = append(diagnostics, Diagnostic{Severity: SeverityWarning, File: file.Name, Message: message})
+			}
+			continue
+		}
+		log.Printf("Detected '%s' file format as %s.", file.Name, parserType.Name)
+		filesByType[parserType.Name] = append(filesByType[parserType.Name], file)
+	}
+	return filesByType, diagnostics, nil
+}
+
+// getSourceFiles returns sorted files matching any glob of the source, each file only once.
+func getSourceFiles(source Source) ([]string, error) {
+	files := []string{}
+	isAdded := map[string]bool{}
+	for _, glob := range source.Globs {
+		if glob == stdinFileName {
+			files = append(files, stdinFileName)
+			continue
+		}
+		globFiles, err := getFilesByGlob(glob)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range globFiles {
+			if !isAdded[file] {
+				isAdded[file] = true
+				files = append(files, file)
+			}
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// applySourceSettings sets account label and currency of the source to transactions and statements.
+func applySourceSettings(source Source, transactions []Transaction, statements []AccountStatement) {
+	for i := range statements {
+		if source.Account != "" {
+			statements[i].Account = source.Account
+		}
+		if source.Currency != "" {
+			statements[i].Currency = source.Currency
+		}
+	}
+	for i := range transactions {
+		if source.Account != "" {
+			transactions[i].Account = source.Account
+		}
+		if source.Currency != "" {
+			transactions[i].Currency = source.Currency
+		}
+	}
+}
+
 func fatalError(err string, inFile bool) {
 	if inFile {
 		writeAndOpenFile(resultFilePath, err)
@@ -173,29 +576,39 @@ func writeAndOpenFile(resultFilePath, content string) {
 	}
 }
 
-// parseTransactionFiles parses transactions from files by glob pattern.
-// Returns list of transactions, not fatal error message and error if it is fatal.
-func parseTransactionFiles(glog string, parser FileParser) ([]Transaction, string, error) {
-	files, err := getFilesByGlob(glog)
+// writeDiagnostics writes diagnostics in JSON into the file or into STDOUT for `stdinFileName` path.
+func writeDiagnostics(path string, diagnostics []Diagnostic) error {
+	content, err := DiagnosticsToJson(diagnostics)
 	if err != nil {
-		return nil, "", err
+		return err
+	}
+	if path == stdinFileName {
+		_, err = fmt.Print(content)
+		return err
 	}
+	return os.WriteFile(path, []byte(content), 0644)
+}
 
-	result := make([]Transaction, 0)
-	notFatalError := ""
-	for _, file := range files {
-		log.Printf("Parsing '%s' with %v parser.", file, parser)
-		rawTransactions, err := parser.ParseRawTransactionsFromFile(file)
-		if err != nil {
-			notFatalError = fmt.Sprintf("Can't parse transactions from '%s' file: %#v", file, err)
-			log.Println(notFatalError)
+// parseFile parses transactions and statement (if parser provides it) from the file.
+// Files from containers and `stdinFileName` file are read by [main.ReaderParser] parsers.
+func parseFile(file sourceFile, parser FileParser) ([]Transaction, *AccountStatement, error) {
+	if file.IsEntry || file.Name == stdinFileName {
+		var reader io.Reader = os.Stdin
+		if file.IsEntry {
+			reader = bytes.NewReader(file.Data)
 		}
-		if len(rawTransactions) < 1 {
-			notFatalError = fmt.Sprintf("Can't find transactions in '%s' file.", file)
-			log.Println(notFatalError)
+		switch readerParser := parser.(type) {
+		case StatementReaderParser:
+			return readerParser.ParseStatement(file.Name, reader)
+		case ReaderParser:
+			transactions, err := readerParser.ParseRawTransactions(file.Name, reader)
+			return transactions, nil, err
 		}
-		log.Printf("Found %d transactions in '%s' file.", len(rawTransactions), file)
-		result = append(result, rawTransactions...)
+		return nil, nil, fmt.Errorf("%T parser can't read '%s'", parser, file.Name)
+	}
+	if statementParser, ok := parser.(StatementFileParser); ok {
+		return statementParser.ParseStatementFromFile(file.Name)
 	}
-	return result, notFatalError, nil
+	transactions, err := parser.ParseRawTransactionsFromFile(file.Name)
+	return transactions, nil, err
 }
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ManualDateFormat is a format of dates in files with manual transactions.
+const ManualDateFormat = "2006-01-02"
+
+// manualYamlKey is a key of the list of transactions in YAML file with manual transactions.
+const manualYamlKey = "transactions:"
+
+// manualCsvRequiredColumns are columns which CSV file with manual transactions must have.
+var manualCsvRequiredColumns = []string{"date", "amount", "details"}
+
+// manualTransaction is a transaction from the file maintained by user, e.g. spending of cash.
+type manualTransaction struct {
+	Date     string `yaml:"date"`
+	Amount   string `yaml:"amount"`
+	Currency string `yaml:"currency,omitempty"`
+	Details  string `yaml:"details"`
+	// Group is a name of the group for the transaction, rules are used if it is empty.
+	Group   string `yaml:"group,omitempty"`
+	Account string `yaml:"account,omitempty"`
+	Income  bool   `yaml:"income,omitempty"`
+	// Cash marks expense paid by cash from the previous cash withdrawal, see `AllocateCashSpending`.
+	Cash bool `yaml:"cash,omitempty"`
+}
+
+// manualFile is a structure of YAML file with manual transactions.
+type manualFile struct {
+	Transactions []yaml.Node `yaml:"transactions"`
+}
+
+// ManualFileParser parses transactions which user writes manually, like spending of cash, in YAML
+// file with "transactions" list or in CSV file with "date", "amount", "details" and optional
+// "currency", "group", "account", "income" and "cash" columns. Amounts are positive.
+type ManualFileParser struct {
+	// Currency is used for transactions without currency.
+	Currency string
+}
+
+func (p ManualFileParser) ParseRawTransactionsFromFile(filePath string) ([]Transaction, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+	return p.ParseRawTransactions(filePath, file)
+}
+
+// ParseRawTransactions implements ReaderParser.
+func (p ManualFileParser) ParseRawTransactions(name string, reader io.Reader) ([]Transaction, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read '%s': %w", name, err)
+	}
+	data = bytes.TrimPrefix(data, bomUTF8)
+	if isManualYaml(data) {
+		return p.parseYaml(name, data)
+	}
+	return p.parseCsv(name, data)
+}
+
+func (p ManualFileParser) parseYaml(name string, data []byte) ([]Transaction, error) {
+	var file manualFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse '%s' as YAML: %w", name, err)
+	}
+	transactions := []Transaction{}
+	diagnostics := Diagnostics{}
+	for _, node := range file.Transactions {
+		raw, _ := yaml.Marshal(&node)
+		rawLine := strings.Join(strings.Fields(string(raw)), " ")
+		var item manualTransaction
+		if err := node.Decode(&item); err != nil {
+			diagnostics = append(diagnostics, newRowDiagnostic(name, node.Line, rawLine, "Can't parse transaction: %v", err))
+			continue
+		}
+		transaction, err := item.toTransaction(p.Currency)
+		if err != nil {
+			diagnostics = append(diagnostics, newRowDiagnostic(name, node.Line, rawLine, "%v", err))
+			continue
+		}
+		transactions = append(transactions, transaction)
+	}
+	if len(diagnostics) > 0 {
+		return transactions, diagnostics
+	}
+	return transactions, nil
+}
+
+func (p ManualFileParser) parseCsv(name string, data []byte) ([]Transaction, error) {
+	csvReader := csv.NewReader(bytes.NewReader(data))
+	csvReader.FieldsPerRecord = -1
+	csvReader.TrimLeadingSpace = true
+	header, err := csvReader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header of '%s': %w", name, err)
+	}
+	columns := map[string]int{}
+	for i, column := range header {
+		columns[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+	for _, column := range manualCsvRequiredColumns {
+		if _, ok := columns[column]; !ok {
+			return nil, fmt.Errorf("'%s' doesn't have '%s' column, header is %v", name, column, header)
+		}
+	}
+	lines := strings.Split(string(data), "\n")
+	transactions := []Transaction{}
+	diagnostics := Diagnostics{}
+	for {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		line, _ := csvReader.FieldPos(0)
+		var parseError *csv.ParseError
+		if errors.As(err, &parseError) {
+			line = parseError.StartLine
+		}
+		raw := ""
+		if line > 0 && line <= len(lines) {
+			raw = strings.TrimRight(lines[line-1], "\r")
+		}
+		if err != nil {
+			diagnostics = append(diagnostics, newRowDiagnostic(name, line, raw, "Can't read row: %v", err))
+			continue
+		}
+		value := func(column string) string {
+			if i, ok := columns[column]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		item := manualTransaction{
+			Date:     value("date"),
+			Amount:   value("amount"),
+			Currency: value("currency"),
+			Details:  value("details"),
+			Group:    value("group"),
+			Account:  value("account"),
+		}
+		item.Income, err = parseManualFlag(value("income"))
+		if err == nil {
+			item.Cash, err = parseManualFlag(value("cash"))
+		}
+		if err != nil {
+			diagnostics = append(diagnostics, newRowDiagnostic(name, line, raw, "%v", err))
+			continue
+		}
+		transaction, err := item.toTransaction(p.Currency)
+		if err != nil {
+			diagnostics = append(diagnostics, newRowDiagnostic(name, line, raw, "%v", err))
+			continue
+		}
+		transactions = append(transactions, transaction)
+	}
+	if len(diagnostics) > 0 {
+		return transactions, diagnostics
+	}
+	return transactions, nil
+}
+
+// parseManualFlag parses boolean column of CSV file, empty value is false.
+func parseManualFlag(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "", "no":
+		return false, nil
+	case "yes", "x":
+		return true, nil
+	}
+	flag, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("can't parse '%s' as yes/no value", value)
+	}
+	return flag, nil
+}
+
+func (t manualTransaction) toTransaction(defaultCurrency string) (Transaction, error) {
+	date, err := time.Parse(ManualDateFormat, t.Date)
+	if err != nil {
+		return Transaction{}, fmt.Errorf("can't parse date '%s', expected YYYY-MM-DD", t.Date)
+	}
+	currency := t.Currency
+	if currency == "" {
+		currency = defaultCurrency
+	}
+	amount, err := parseMoney(t.Amount, currency)
+	if err != nil {
+		return Transaction{}, fmt.Errorf("can't parse amount: %w", err)
+	}
+	if amount.int <= 0 {
+		return Transaction{}, fmt.Errorf("amount '%s' should be positive, use 'income' to mark incomes", t.Amount)
+	}
+	if strings.TrimSpace(t.Details) == "" {
+		return Transaction{}, fmt.Errorf("details are empty")
+	}
+	if t.Cash && t.Income {
+		return Transaction{}, fmt.Errorf("income can't be spending of cash")
+	}
+	return Transaction{
+		IsExpense: !t.Income,
+		Date:      date,
+		Details:   t.Details,
+		Amount:    amount,
+		Account:   t.Account,
+		Currency:  currency,
+		Group:     t.Group,
+		IsCash:    t.Cash,
+	}, nil
+}
+
+// isManualYaml checks that the first meaningful line of the data is the list of manual transactions.
+func isManualYaml(data []byte) bool {
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || line == "---" {
+			continue
+		}
+		return strings.HasPrefix(line, manualYamlKey)
+	}
+	return false
+}
+
+func sniffManual(head []byte, _ func() ([]byte, error)) bool {
+	head = bytes.TrimPrefix(head, bomUTF8)
+	if isManualYaml(head) {
+		return true
+	}
+	firstLine, _, _ := strings.Cut(string(head), "\n")
+	columns := map[string]bool{}
+	for _, column := range strings.Split(firstLine, ",") {
+		columns[strings.ToLower(strings.TrimSpace(column))] = true
+	}
+	for _, column := range manualCsvRequiredColumns {
+		if !columns[column] {
+			return false
+		}
+	}
+	return true
+}
+
+var _ FileParser = ManualFileParser{}
+var _ ReaderParser = ManualFileParser{}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const manualYaml = `# Cash spending.
+transactions:
+  - date: 2024-03-02
+    amount: 4500
+    details: Market on Komitas
+    group: Groceries
+    cash: true
+  - date: 2024-03-05
+    amount: "1,200.50"
+    currency: USD
+    details: Sold bicycle
+    income: true
+`
+
+const manualCsv = "Date,Amount,Details,Group,Cash\r\n" +
+	"2024-03-02,4500,Market on Komitas,Groceries,yes\r\n" +
+	"2024-03-05,\"1,200.50\",Sold bicycle,,\r\n"
+
+func TestManualFileParser_ParseRawTransactions(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected []Transaction
+	}{
+		{
+			"yaml",
+			manualYaml,
+			[]Transaction{
+				{IsExpense: true, Date: time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC), Details: "Market on Komitas",
+					Amount: MoneyWith2DecimalPlaces{45000000}, Currency: "AMD", Group: "Groceries", IsCash: true},
+				{IsExpense: false, Date: time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC), Details: "Sold bicycle",
+					Amount: MoneyWith2DecimalPlaces{12005000}, Currency: "USD"},
+			},
+		},
+		{
+			"csv",
+			manualCsv,
+			[]Transaction{
+				{IsExpense: true, Date: time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC), Details: "Market on Komitas",
+					Amount: MoneyWith2DecimalPlaces{45000000}, Currency: "AMD", Group: "Groceries", IsCash: true},
+				{IsExpense: true, Date: time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC), Details: "Sold bicycle",
+					Amount: MoneyWith2DecimalPlaces{12005000}, Currency: "AMD"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// Act
+			actual, err := ManualFileParser{Currency: "AMD"}.ParseRawTransactions("cash", strings.NewReader(tt.content))
+
+			// Assert
+			if err != nil {
+				t.Fatalf("ParseRawTransactions() failed: %v", err)
+			}
+			if len(actual) != len(tt.expected) {
+				t.Fatalf("Expected %d transactions, got %+v", len(tt.expected), actual)
+			}
+			for i, expected := range tt.expected {
+				a := actual[i]
+				if a.IsExpense != expected.IsExpense || !a.Date.Equal(expected.Date) || a.Details != expected.Details ||
+					a.Amount != expected.Amount || a.Currency != expected.Currency || a.Group != expected.Group ||
+					a.IsCash != expected.IsCash {
+					t.Errorf("Transaction %d:\n got %+v\nwant %+v", i, a, expected)
+				}
+			}
+		})
+	}
+}
+
+func TestManualFileParser_Diagnostics(t *testing.T) {
+	tests := []struct {
+		name             string
+		content          string
+		expectedCount    int
+		expectedLines    []int
+		expectedMessages []string
+	}{
+		{
+			"yaml",
+			`transactions:
+  - date: 2024-03-02
+    amount: 100
+    details: Fine
+  - date: 02.03.2024
+    amount: 100
+    details: Wrong date
+  - date: 2024-03-02
+    amount: -100
+    details: Negative
+  - date: 2024-03-02
+    amount: 100
+    details: Cash income
+    cash: true
+    income: true
+`,
+			1,
+			[]int{5, 8, 11},
+			[]string{"can't parse date", "should be positive", "income can't be spending of cash"},
+		},
+		{
+			"csv",
+			"date,amount,details,cash\n2024-03-02,100,Fine,\n2024-03-02,abc,Wrong amount,\n2024-03-02,100,,\n2024-03-02,100,Wrong flag,maybe\n",
+			1,
+			[]int{3, 4, 5},
+			[]string{"can't parse amount", "details are empty", "can't parse 'maybe'"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// Act
+			transactions, err := ManualFileParser{}.ParseRawTransactions("cash", strings.NewReader(tt.content))
+
+			// Assert
+			if len(transactions) != tt.expectedCount {
+				t.Errorf("Expected %d transactions, got %d", tt.expectedCount, len(transactions))
+			}
+			diagnostics, ok := err.(Diagnostics)
+			if !ok {
+				t.Fatalf("Expected diagnostics, got %v", err)
+			}
+			if len(diagnostics) != len(tt.expectedLines) {
+				t.Fatalf("Expected %d diagnostics, got %v", len(tt.expectedLines), diagnostics)
+			}
+			for i, d := range diagnostics {
+				if d.Line != tt.expectedLines[i] || !strings.Contains(d.Message, tt.expectedMessages[i]) || d.Raw == "" {
+					t.Errorf("Expected diagnostic at line %d with %q, got %+v", tt.expectedLines[i], tt.expectedMessages[i], d)
+				}
+			}
+		})
+	}
+}
+
+func TestManualFileParser_WrongHeader(t *testing.T) {
+
+	// Act
+	_, err := ManualFileParser{}.ParseRawTransactions("cash.csv", strings.NewReader("date,sum,details\n"))
+
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), "doesn't have 'amount' column") {
+		t.Errorf("Expected error about missing column, got %v", err)
+	}
+}
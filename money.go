@@ -0,0 +1,246 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// defaultCurrencyPrecision is a number of minor unit digits of currencies not listed in `currencyPrecisions`.
+const defaultCurrencyPrecision = 2
+
+// maxCurrencyPrecision is the maximum number of minor unit digits, see 'currencyPrecisions' setting.
+const maxCurrencyPrecision = 4
+
+// amountsPrecision is a number of digits after the decimal separator kept in `MoneyWith2DecimalPlaces`.
+// It is enough to don't lose minor units of any currency.
+const amountsPrecision = maxCurrencyPrecision
+
+// outputPrecision is a number of digits after the decimal separator in reports, see `setOutputPrecision`.
+var outputPrecision = defaultCurrencyPrecision
+
+// currencyPrecisions are numbers of minor unit digits of currencies which don't have 2 ones
+// (ISO 4217). May be extended with 'currencyPrecisions' setting.
+var currencyPrecisions = map[string]int{
+	"BHD": 3,
+	"CLP": 0,
+	"IQD": 3,
+	"ISK": 0,
+	"JOD": 3,
+	"JPY": 0,
+	"KRW": 0,
+	"KWD": 3,
+	"LYD": 3,
+	"OMR": 3,
+	"TND": 3,
+	"VND": 0,
+}
+
+// setCurrencyPrecisions adds or overrides precisions of currencies. Should be called before parsing.
+func setCurrencyPrecisions(precisions map[string]uint) {
+	for currency, precision := range precisions {
+		currencyPrecisions[strings.ToUpper(currency)] = int(precision)
+	}
+}
+
+// currencyPrecision returns number of minor unit digits of the currency, 2 for unknown ones.
+func currencyPrecision(currency string) int {
+	if precision, ok := currencyPrecisions[strings.ToUpper(strings.TrimSpace(currency))]; ok {
+		return precision
+	}
+	return defaultCurrencyPrecision
+}
+
+// setOutputPrecision sets number of digits in reports to the maximum precision of currencies of
+// transactions and statements, e.g. JPY amounts are printed without decimals and KWD ones with 3 digits.
+func setOutputPrecision(transactions []Transaction, statements []AccountStatement) {
+	precision := -1
+	for _, t := range transactions {
+		if p := currencyPrecision(t.Currency); p > precision {
+			precision = p
+		}
+	}
+	for _, s := range statements {
+		if p := currencyPrecision(s.Currency); p > precision {
+			precision = p
+		}
+	}
+	if precision < 0 {
+		precision = defaultCurrencyPrecision
+	}
+	if precision > amountsPrecision {
+		precision = amountsPrecision
+	}
+	outputPrecision = precision
+}
+
+// pow10 returns 10 to the power of `n`.
+func pow10(n int) int {
+	result := 1
+	for ; n > 0; n-- {
+		result *= 10
+	}
+	return result
+}
+
+// UnmarshalText parses amount with up to `amountsPrecision` digits after the decimal separator, see `parseDecimal`.
+// Amounts in configuration and store have no currency so they are limited by the maximum precision
+// only, use `parseMoney` for amounts in the known currency.
+func (m *MoneyWith2DecimalPlaces) UnmarshalText(text []byte) error {
+	value, err := parseDecimal(string(text), amountsPrecision)
+	if err != nil {
+		return err
+	}
+	m.int = value
+	return nil
+}
+
+// MarshalText returns exact amount like "-1500.25" or "1.125" which `UnmarshalText` parses back.
+// Trailing zeros are trimmed up to 2 digits after the decimal separator.
+func (m MoneyWith2DecimalPlaces) MarshalText() ([]byte, error) {
+	value := m.int
+	sign := ""
+	if value < 0 {
+		sign = "-"
+		value = -value
+	}
+	divisor := pow10(amountsPrecision)
+	fraction := fmt.Sprintf("%0*d", amountsPrecision, value%divisor)
+	for len(fraction) > defaultCurrencyPrecision && strings.HasSuffix(fraction, "0") {
+		fraction = fraction[:len(fraction)-1]
+	}
+	if fraction == "" {
+		return []byte(fmt.Sprintf("%s%d", sign, value/divisor)), nil
+	}
+	return []byte(fmt.Sprintf("%s%d.%s", sign, value/divisor, fraction)), nil
+}
+
+// parseMoney parses amount in the currency, see `parseDecimal`. Returns error if amount has more
+// digits after the decimal separator than the currency has.
+func parseMoney(text string, currency string) (MoneyWith2DecimalPlaces, error) {
+	precision := currencyPrecision(currency)
+	value, err := parseDecimal(text, precision)
+	if err != nil {
+		return MoneyWith2DecimalPlaces{}, err
+	}
+	return MoneyWith2DecimalPlaces{changePrecision(value, precision, amountsPrecision)}, nil
+}
+
+// parseDecimal parses exact decimal number like "1,500.25", "1 500,25", "1'500.25" or "-1500"
+// into integer number of minor units with `precision` digits, e.g. 150025 for 2 digits.
+// Spaces, apostrophes and single ',' followed by exactly 3 digits (like "1,500")
+// are treated as group separators. Returns error if number has more non-zero digits after
+// the decimal separator than `precision`.
+func parseDecimal(text string, precision int) (int, error) {
+	s := strings.TrimSpace(text)
+	isNegative := false
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		isNegative = s[0] == '-'
+		s = strings.TrimSpace(s[1:])
+	}
+	hasGroupSpaces := false
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || r == '\'' {
+			hasGroupSpaces = true
+			return -1
+		}
+		return r
+	}, s)
+	integerPart, fractionPart, err := splitDecimal(s, hasGroupSpaces)
+	if err != nil {
+		return 0, fmt.Errorf("can't parse '%s' as a number: %w", text, err)
+	}
+	if integerPart == "" && fractionPart == "" {
+		return 0, fmt.Errorf("can't parse '%s' as a number: no digits", text)
+	}
+	trimmedFraction := strings.TrimRight(fractionPart, "0")
+	if len(trimmedFraction) > precision {
+		return 0, fmt.Errorf("'%s' has more than %d digits after the decimal separator", text, precision)
+	}
+	fractionPart = trimmedFraction + strings.Repeat("0", precision-len(trimmedFraction))
+	value := 0
+	for _, r := range integerPart + fractionPart {
+		if r < '0' || r > '9' {
+			return 0, fmt.Errorf("can't parse '%s' as a number: unexpected '%c'", text, r)
+		}
+		if value > (math.MaxInt-int(r-'0'))/10 {
+			return 0, fmt.Errorf("'%s' is too big", text)
+		}
+		value = value*10 + int(r-'0')
+	}
+	if isNegative {
+		value = -value
+	}
+	return value, nil
+}
+
+// splitDecimal returns integer and fraction parts of the number without sign and spaces.
+// Decimal separator is the last of '.' and ',' if number has both. Otherwise it is '.' met once
+// or ',' met once if groups are separated by spaces or it is not followed by exactly 3 digits.
+func splitDecimal(s string, hasGroupSpaces bool) (string, string, error) {
+	lastDot, lastComma := strings.LastIndex(s, "."), strings.LastIndex(s, ",")
+	var decimalSeparator, groupSeparator string
+	switch {
+	case lastDot >= 0 && lastComma >= 0:
+		decimalSeparator, groupSeparator = ".", ","
+		if lastComma > lastDot {
+			decimalSeparator, groupSeparator = ",", "."
+		}
+	case lastDot >= 0:
+		decimalSeparator = "."
+		if strings.Count(s, ".") > 1 {
+			decimalSeparator, groupSeparator = "", "."
+		}
+	case lastComma >= 0:
+		decimalSeparator = ","
+		if strings.Count(s, ",") > 1 || (!hasGroupSpaces && len(s)-lastComma-1 == 3) {
+			decimalSeparator, groupSeparator = "", ","
+		}
+	}
+	integerPart, fractionPart := s, ""
+	if decimalSeparator != "" {
+		index := strings.LastIndex(s, decimalSeparator)
+		integerPart, fractionPart = s[:index], s[index+1:]
+	}
+	if groupSeparator != "" {
+		groups := strings.Split(integerPart, groupSeparator)
+		for i, group := range groups {
+			if len(groups) > 1 && (len(group) > 3 || (i > 0 && len(group) < 3) || group == "") {
+				return "", "", fmt.Errorf("wrong position of '%s' group separator", groupSeparator)
+			}
+		}
+		integerPart = strings.Join(groups, "")
+	}
+	if strings.ContainsAny(fractionPart, ".,") {
+		return "", "", fmt.Errorf("a few decimal separators")
+	}
+	return integerPart, fractionPart, nil
+}
+
+// changePrecision converts number of minor units with `from` digits into number with `to` digits.
+// Rounds half to even if digits are lost.
+func changePrecision(value int, from int, to int) int {
+	for ; from < to; from++ {
+		value *= 10
+	}
+	if from == to {
+		return value
+	}
+	divisor := 1
+	for ; from > to; from-- {
+		divisor *= 10
+	}
+	quotient, remainder := value/divisor, value%divisor
+	if remainder < 0 {
+		remainder = -remainder
+	}
+	if remainder*2 > divisor || (remainder*2 == divisor && quotient%2 != 0) {
+		if value < 0 {
+			quotient--
+		} else {
+			quotient++
+		}
+	}
+	return quotient
+}
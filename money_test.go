@@ -0,0 +1,278 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/quick"
+)
+
+func TestMoneyWith2DecimalPlaces_UnmarshalText(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantInt int
+		wantErr bool
+	}{
+		{"valid input", "123.45", 1234500, false},
+		{"input with more decimal places", "123.45678", 0, true},
+		{"input with trailing zeros", "123.450000", 1234500, false},
+		{"input with negative value", "-123.45", -1234500, false},
+		{"value broken by float", "0.29", 2900, false},
+		{"big value broken by float", "1,234,567.89", 12345678900, false},
+		{"groups only", "1,500", 15000000, false},
+		{"locale with spaces", "1 500,00", 15000000, false},
+		{"locale with non-breaking spaces", "1\u00a0500\u202f000,5", 15000005000, false},
+		{"locale with dots", "1.500.000,25", 15000002500, false},
+		{"locale with apostrophes", "1'500.25", 15002500, false},
+		{"decimal comma", "0,5", 5000, false},
+		{"without integer part", ".5", 5000, false},
+		{"with plus and spaces", " + 12 ", 120000, false},
+		{"wrong groups", "1,50,000.00", 0, true},
+		{"a few decimal separators", "1.5.0,0", 0, true},
+		{"empty", "", 0, true},
+		{"only sign", "-", 0, true},
+		{"invalid input", "abc", 0, true},
+		{"too big", "99999999999999999999", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m MoneyWith2DecimalPlaces
+			err := m.UnmarshalText([]byte(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: got %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && m.int != tt.wantInt {
+				t.Errorf("got int %d, want %d", m.int, tt.wantInt)
+			}
+		})
+	}
+}
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		input    string
+		currency string
+		wantInt  int
+		wantErr  bool
+	}{
+		{"1,500", "JPY", 15000000, false},
+		{"1500.5", "JPY", 0, true},
+		{"1.234", "KWD", 12340, false},
+		{"1.235", "KWD", 12350, false},
+		{"1.245", "KWD", 12450, false},
+		{"-1.235", "KWD", -12350, false},
+		{"1.2345", "KWD", 0, true},
+		{"499.50", "AMD", 4995000, false},
+		{"499.50", "", 4995000, false},
+		{"499.505", "usd", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.currency+" "+tt.input, func(t *testing.T) {
+
+			// Act
+			actual, err := parseMoney(tt.input, tt.currency)
+
+			// Assert
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: got %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && actual.int != tt.wantInt {
+				t.Errorf("got int %d, want %d", actual.int, tt.wantInt)
+			}
+		})
+	}
+}
+
+func TestSetCurrencyPrecisions(t *testing.T) {
+	// Arrange
+	defer func(precisions map[string]int) { currencyPrecisions = precisions }(currencyPrecisions)
+	currencyPrecisions = map[string]int{"JPY": 0}
+
+	// Act
+	setCurrencyPrecisions(map[string]uint{"btc": 4, "JPY": 2})
+
+	// Assert
+	expected := map[string]int{"JPY": 2, "BTC": 4}
+	if !reflect.DeepEqual(currencyPrecisions, expected) {
+		t.Errorf("currencyPrecisions = %v, want %v", currencyPrecisions, expected)
+	}
+	if actual, err := parseMoney("0.0001", "BTC"); err != nil || actual.int != 1 {
+		t.Errorf("parseMoney() = %d, %v, want 1", actual.int, err)
+	}
+}
+
+func TestMoneyWith2DecimalPlaces_MarshalText(t *testing.T) {
+	// Act
+	kwd, kwdErr := parseMoney("1.235", "KWD")
+	amd, amdErr := parseMoney("499.50", "AMD")
+
+	// Assert
+	if kwdErr != nil || kwd.int != 12350 {
+		t.Errorf("parseMoney() = %v, %v, want 12350", kwd.int, kwdErr)
+	}
+	if amdErr != nil || amd.int != 4995000 {
+		t.Errorf("parseMoney() = %v, %v, want 4995000", amd.int, amdErr)
+	}
+	if text, _ := kwd.MarshalText(); string(text) != "1.235" {
+		t.Errorf("MarshalText() = %q, want \"1.235\"", text)
+	}
+	if text, _ := amd.MarshalText(); string(text) != "499.50" {
+		t.Errorf("MarshalText() = %q, want \"499.50\"", text)
+	}
+}
+
+func TestMoneyWith2DecimalPlaces_String_OutputPrecision(t *testing.T) {
+	defer func(precision int) { outputPrecision = precision }(outputPrecision)
+	tests := []struct {
+		name       string
+		currencies []string
+		value      int
+		expected   string
+	}{
+		{"jpy", []string{"JPY"}, 15000000, "    1,500"},
+		{"kwd", []string{"KWD", "AMD"}, -12350, "       -1.235"},
+		{"default", []string{"AMD", ""}, 4995000, "      499.50"},
+		{"mixed", []string{"JPY", "AMD"}, 15000000, "    1,500.00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			transactions := []Transaction{}
+			for _, currency := range tt.currencies {
+				transactions = append(transactions, Transaction{Currency: currency})
+			}
+
+			// Act
+			setOutputPrecision(transactions, nil)
+
+			// Assert
+			if actual := (MoneyWith2DecimalPlaces{tt.value}).String(); actual != tt.expected {
+				t.Errorf("String() = %q, want %q", actual, tt.expected)
+			}
+		})
+	}
+}
+
+// moneyLocales are pairs of group and decimal separators used to format numbers.
+var moneyLocales = [][2]string{{",", "."}, {" ", ","}, {"\u00a0", ","}, {".", ","}, {"'", "."}, {"", "."}, {"", ","}}
+
+// formatDecimal formats number of minor units with `precision` digits using the locale.
+func formatDecimal(value int64, precision int, locale [2]string) string {
+	sign := ""
+	if value < 0 {
+		sign = "-"
+		value = -value
+	}
+	digits := fmt.Sprintf("%0*d", precision+1, value)
+	integerPart, fractionPart := digits[:len(digits)-precision], digits[len(digits)-precision:]
+	for i := len(integerPart) - 3; i > 0; i -= 3 {
+		integerPart = integerPart[:i] + locale[0] + integerPart[i:]
+	}
+	if precision == 0 {
+		return sign + integerPart
+	}
+	return sign + integerPart + locale[1] + fractionPart
+}
+
+// isAmbiguousDecimal checks that number has the only separator followed by 3 digits
+// which may be both group and decimal separator.
+func isAmbiguousDecimal(text string) bool {
+	if strings.ContainsAny(text, " \u00a0'") || strings.Count(text, ",")+strings.Count(text, ".") != 1 {
+		return false
+	}
+	index := strings.LastIndexAny(text, ".,")
+	return len(text)-index-1 == 3
+}
+
+func TestParseDecimal_Properties(t *testing.T) {
+	config := &quick.Config{MaxCount: 2000, Rand: rand.New(rand.NewSource(1))}
+
+	t.Run("string_round_trip", func(t *testing.T) {
+		property := func(value int64) bool {
+			money := MoneyWith2DecimalPlaces{changePrecision(int(value%1e15), outputPrecision, amountsPrecision)}
+			var parsed MoneyWith2DecimalPlaces
+			err := parsed.UnmarshalText([]byte(money.String()))
+			return err == nil && parsed == money
+		}
+		if err := quick.Check(property, config); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("text_round_trip", func(t *testing.T) {
+		property := func(value int64) bool {
+			money := MoneyWith2DecimalPlaces{int(value % 1e15)}
+			text, err := money.MarshalText()
+			if err != nil {
+				return false
+			}
+			var parsed MoneyWith2DecimalPlaces
+			err = parsed.UnmarshalText(text)
+			return err == nil && parsed == money
+		}
+		if err := quick.Check(property, config); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("any_locale_and_precision", func(t *testing.T) {
+		property := func(value int64, precision uint8, localeIndex uint8) bool {
+			value %= 1e15
+			p := int(precision % 5)
+			text := formatDecimal(value, p, moneyLocales[int(localeIndex)%len(moneyLocales)])
+			if isAmbiguousDecimal(text) {
+				return true
+			}
+			parsed, err := parseDecimal(text, p)
+			if err != nil || parsed != int(value) {
+				t.Logf("parseDecimal(%q, %d) = %d, %v, want %d", text, p, parsed, err, value)
+				return false
+			}
+			return true
+		}
+		if err := quick.Check(property, config); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("extra_digits_are_rejected", func(t *testing.T) {
+		property := func(value int64, precision uint8) bool {
+			p := int(precision % 4)
+			value %= 1e15
+			if value%10 == 0 {
+				value++ // Trailing zeros are allowed.
+			}
+			_, err := parseDecimal(formatDecimal(value, p+1, [2]string{",", "."}), p)
+			return err != nil
+		}
+		if err := quick.Check(property, config); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("precision_change_round_trip", func(t *testing.T) {
+		property := func(value int32, from uint8, delta uint8) bool {
+			f := int(from % 4)
+			to := f + int(delta%3)
+			return changePrecision(changePrecision(int(value), f, to), to, f) == int(value)
+		}
+		if err := quick.Check(property, config); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("rounding_error_is_at_most_half", func(t *testing.T) {
+		property := func(value int32) bool {
+			rounded := changePrecision(int(value), 3, 2)
+			difference := rounded*10 - int(value)
+			return difference >= -5 && difference <= 5
+		}
+		if err := quick.Check(property, config); err != nil {
+			t.Error(err)
+		}
+	})
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// sniffHeadSize is a number of bytes from the beginning of the file enough to recognize its format.
+const sniffHeadSize = 4096
+
+// FileParserType is a supported format of files.
+type FileParserType struct {
+	// Name is a value of `Source.Type` in configuration.
+	Name string
+	// NewParser returns parser with settings from the source.
+	NewParser func(source Source) FileParser
+	// Sniff checks that file has supported format by the beginning of the file.
+	// If it is not enough then may read the whole file with `readAll`.
+	Sniff func(head []byte, readAll func() ([]byte, error)) bool
+}
+
+// fileParserTypes is a registry of all supported formats.
+var fileParserTypes = []FileParserType{
+	{
+		Name:      SourceTypeInecobankXml,
+		NewParser: func(Source) FileParser { return InecoXmlParser{} },
+		Sniff:     sniffInecoXml,
+	},
+	{
+		Name: SourceTypeMyAmeriaExcel,
+		NewParser: func(source Source) FileParser {
+			return MyAmeriaExcelFileParser{
+				MyAccounts:              source.MyAccounts,
+				DetailsIncomeSubstrings: source.IncomeSubstrings,
+			}
+		},
+		Sniff: sniffMyAmeriaExcel,
+	},
+	{
+		Name:      SourceTypeAmeriaCsv,
+		NewParser: func(source Source) FileParser { return AmeriaCsvFileParser{Currency: source.Currency} },
+		Sniff:     sniffAmeriaCsv,
+	},
+	{
+		Name:      SourceTypeManual,
+		NewParser: func(source Source) FileParser { return ManualFileParser{Currency: source.Currency} },
+		Sniff:     sniffManual,
+	},
+}
+
+// findFileParserType returns registered format by name.
+func findFileParserType(name string) (FileParserType, error) {
+	for _, parserType := range fileParserTypes {
+		if parserType.Name == name {
+			return parserType, nil
+		}
+	}
+	return FileParserType{}, fmt.Errorf("unknown type '%s'", name)
+}
+
+// detectFileParserType returns format of the file or nil if format is not supported.
+func detectFileParserType(file sourceFile) (*FileParserType, error) {
+	var head []byte
+	var readAll func() ([]byte, error)
+	if file.IsEntry {
+		head = file.Data
+		if len(head) > sniffHeadSize {
+			head = head[:sniffHeadSize]
+		}
+		readAll = func() ([]byte, error) { return file.Data, nil }
+	} else {
+		f, err := os.Open(file.Name)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		head = make([]byte, sniffHeadSize)
+		n, err := io.ReadFull(f, head)
+		if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+			return nil, err
+		}
+		head = head[:n]
+		readAll = func() ([]byte, error) { return os.ReadFile(file.Name) }
+	}
+	for i := range fileParserTypes {
+		if fileParserTypes[i].Sniff(head, readAll) {
+			return &fileParserTypes[i], nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+func TestDetectFileParserType(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	csvHeader := "\"" + strings.Join(csvHeaders, "\"\t\"") + "\"\r\n"
+	csvFile := filepath.Join(dir, "export.csv")
+	if err := os.WriteFile(csvFile, encodeUTF16LE("\ufeff"+csvHeader), 0644); err != nil {
+		t.Fatal(err)
+	}
+	csvBigEndianFile := filepath.Join(dir, "export_be.csv")
+	if err := os.WriteFile(csvBigEndianFile, encodeUTF16BE("\ufeff"+csvHeader), 0644); err != nil {
+		t.Fatal(err)
+	}
+	writeFiles(t, dir, map[string]string{
+		"other.xml":   "<?xml version=\"1.0\"?>\n<Report><Statement/></Report>",
+		"notes.txt":   "Date\tTransaction Type",
+		"resaved.csv": strings.Join(csvHeaders, ";") + "\r\n01/08/2023;Transfer;1;157;1.00;0.00;LLC;Օգոստոս\r\n",
+		"cash.yaml":   manualYaml,
+		"cash.csv":    manualCsv,
+	})
+	tests := []struct {
+		file     string
+		expected string
+	}{
+		{"testdata/ineco/valid_statement.xml", SourceTypeInecobankXml},
+		{"testdata/ameria/valid_file.xls", SourceTypeMyAmeriaExcel},
+		{"testdata/ameria/invalid_header.xls", ""},
+		{csvFile, SourceTypeAmeriaCsv},
+		{csvBigEndianFile, SourceTypeAmeriaCsv},
+		{filepath.Join(dir, "resaved.csv"), SourceTypeAmeriaCsv},
+		{filepath.Join(dir, "other.xml"), ""},
+		{filepath.Join(dir, "notes.txt"), ""},
+		{filepath.Join(dir, "cash.yaml"), SourceTypeManual},
+		{filepath.Join(dir, "cash.csv"), SourceTypeManual},
+	}
+	for _, tt := range tests {
+		t.Run(filepath.Base(tt.file), func(t *testing.T) {
+
+			// Act
+			parserType, err := detectFileParserType(sourceFile{Name: tt.file})
+
+			// Assert
+			if err != nil {
+				t.Fatalf("detectFileParserType() failed: %v", err)
+			}
+			actual := ""
+			if parserType != nil {
+				actual = parserType.Name
+			}
+			if actual != tt.expected {
+				t.Errorf("detectFileParserType() = '%s', want '%s'", actual, tt.expected)
+			}
+		})
+	}
+}
+
+func encodeUTF16LE(text string) []byte {
+	result := []byte{}
+	for _, unit := range utf16.Encode([]rune(text)) {
+		result = append(result, byte(unit), byte(unit>>8))
+	}
+	return result
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"sync"
+)
+
+// parseJob is a file to parse with the parser of the source.
+type parseJob struct {
+	file   sourceFile
+	parser FileParser
+	source Source
+	// hash is a hash of the file to import it into `TransactionStore`, empty if it is not imported.
+	hash string
+}
+
+// parseResult is a result of `parseJob`.
+type parseResult struct {
+	transactions []Transaction
+	statement    *AccountStatement
+	err          error
+}
+
+// runParseJobs parses files in up to `workers` goroutines. Results are in the same order as jobs
+// regardless of the order files are parsed in. If specified, `progress` is called after each file
+// with number of already parsed files, calls are made from the caller goroutine one by one.
+func runParseJobs(
+	jobs []parseJob,
+	workers int,
+	progress func(done int, job parseJob, result parseResult),
+) []parseResult {
+	results := make([]parseResult, len(jobs))
+	if workers < 1 {
+		workers = 1
+	}
+	indexes := make(chan int)
+	completed := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < len(jobs); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				transactions, statement, err := parseFile(jobs[i].file, jobs[i].parser)
+				results[i] = parseResult{transactions, statement, err}
+				completed <- i
+			}
+		}()
+	}
+	go func() {
+		for i := range jobs {
+			indexes <- i
+		}
+		close(indexes)
+		wg.Wait()
+		close(completed)
+	}()
+	done := 0
+	for i := range completed {
+		done++
+		if progress != nil {
+			progress(done, jobs[i], results[i])
+		}
+	}
+	return results
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunParseJobs(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	jobs := []parseJob{}
+	for i := 0; i < 20; i++ {
+		content := syntheticInecoStatement(i, i+1)
+		if i%7 == 3 {
+			content = "broken"
+		}
+		file := filepath.Join(dir, fmt.Sprintf("%02d.xml", i))
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		jobs = append(jobs, parseJob{file: sourceFile{Name: file}, parser: InecoXmlParser{}})
+	}
+	progress := []int{}
+
+	// Act
+	results := runParseJobs(jobs, 4, func(done int, job parseJob, result parseResult) {
+		progress = append(progress, done)
+	})
+
+	// Assert
+	if len(results) != len(jobs) {
+		t.Fatalf("Expected %d results, got %d", len(jobs), len(results))
+	}
+	for i, result := range results {
+		if i%7 == 3 {
+			if result.err == nil {
+				t.Errorf("Expected error for %d file", i)
+			}
+			continue
+		}
+		if result.err != nil {
+			t.Errorf("Unexpected error for %d file: %v", i, result.err)
+		} else if len(result.transactions) != i+1 || result.statement.Source != jobs[i].file.Name {
+			t.Errorf("Expected %d transactions from '%s', got %d from '%s'",
+				i+1, jobs[i].file.Name, len(result.transactions), result.statement.Source)
+		}
+	}
+	for i, done := range progress {
+		if done != i+1 {
+			t.Errorf("Expected progress %d, got %v", i+1, progress)
+			break
+		}
+	}
+}
+
+func TestParseAllTransactions_Diagnostics(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"1.xml": inecoValidStatement,
+		"2.xml": inecoValidStatement, // The same statement downloaded twice.
+		"3.xml": "broken",
+	})
+	config := &Config{Sources: []Source{{Type: SourceTypeInecobankXml, Globs: []string{filepath.Join(dir, "*.xml")}}}}
+
+	// Act
+	transactions, _, diagnostics, err := parseAllTransactions(config, nil, true)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("parseAllTransactions() failed: %v", err)
+	}
+	if len(transactions) != 4 {
+		t.Errorf("Expected 4 transactions, got %d", len(transactions))
+	}
+	expected := []struct {
+		severity string
+		file     string
+		message  string
+	}{
+		{SeverityWarning, "2.xml", "Duplicate of transaction from '" + filepath.Join(dir, "1.xml") + "'."},
+		{SeverityWarning, "2.xml", "Duplicate of transaction from '" + filepath.Join(dir, "1.xml") + "'."},
+		{SeverityError, "3.xml", "InecoXmlParser can't parse transactions"},
+	}
+	if len(diagnostics) != len(expected) {
+		t.Fatalf("Expected %d diagnostics, got %v", len(expected), diagnostics)
+	}
+	for i, e := range expected {
+		d := diagnostics[i]
+		if d.Severity != e.severity || d.File != filepath.Join(dir, e.file) || !strings.Contains(d.Message, e.message) {
+			t.Errorf("Expected %s diagnostic about '%s' in %s, got %+v", e.severity, e.message, e.file, d)
+		}
+	}
+}
+
+func BenchmarkRunParseJobs(b *testing.B) {
+	dir := b.TempDir()
+	jobs := []parseJob{}
+	for i := 0; i < 50; i++ {
+		file := filepath.Join(dir, fmt.Sprintf("Statement %03d.xml", i))
+		if err := os.WriteFile(file, []byte(syntheticInecoStatement(i, 500)), 0644); err != nil {
+			b.Fatal(err)
+		}
+		jobs = append(jobs, parseJob{file: sourceFile{Name: file}, parser: InecoXmlParser{}})
+	}
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				for _, result := range runParseJobs(jobs, workers, nil) {
+					if result.err != nil {
+						b.Fatal(result.err)
+					}
+				}
+			}
+		})
+	}
+}
+
+// syntheticInecoStatement returns Inecobank statement for the month with specified number of operations.
+func syntheticInecoStatement(month, operations int) string {
+	start := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC).AddDate(0, month, 0)
+	end := start.AddDate(0, 1, -1)
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<?xml version="1.0" encoding="utf-8"?>
+<Statement>
+  <Client>JOHN DOE</Client>
+  <AccountNumber>2050000000000000</AccountNumber>
+  <Currency>AMD</Currency>
+  <Period>%s - %s</Period>
+  <Openingbalance>0.00</Openingbalance>
+  <Closingbalance>0.00</Closingbalance>
+  <Operations>
+`, start.Format(InecoDateFormat), end.Format(InecoDateFormat))
+	for i := 0; i < operations; i++ {
+		fmt.Fprintf(&sb, `    <Operation>
+      <n-n>%d</n-n>
+      <Number>%d</Number>
+      <Date>%s</Date>
+      <Currency>AMD</Currency>
+      <Income>0.00</Income>
+      <Expense>%d.%02d</Expense>
+      <Receiver-PayerAccount>1930000000000000</Receiver-PayerAccount>
+      <Receiver-Payer>SHOP %d</Receiver-Payer>
+      <Details>PURCHASE IN SHOP %d</Details>
+    </Operation>
+`, i+1, i+1, start.AddDate(0, 0, i%28).Format(InecoDateFormat), i+1, i%100, i%50, i%50)
+	}
+	sb.WriteString("  </Operations>\n</Statement>\n")
+	return sb.String()
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Cadence describes how often a recurring transaction happens.
+type Cadence struct {
+	Name    string
+	Days    int
+	PerYear int
+	// Tolerance is a number of days an interval between transactions may differ from `Days`.
+	Tolerance int
+}
+
+// addTo returns date after `times` periods of cadence. Uses calendar months for monthly-like cadences.
+func (c Cadence) addTo(date time.Time, times int) time.Time {
+	switch c.PerYear {
+	case 12:
+		return date.AddDate(0, times, 0)
+	case 4:
+		return date.AddDate(0, 3*times, 0)
+	case 1:
+		return date.AddDate(times, 0, 0)
+	default:
+		return date.AddDate(0, 0, c.Days*times)
+	}
+}
+
+var cadences = []Cadence{
+	{Name: "weekly", Days: 7, PerYear: 52, Tolerance: 1},
+	{Name: "monthly", Days: 30, PerYear: 12, Tolerance: 4},
+	{Name: "quarterly", Days: 91, PerYear: 4, Tolerance: 8},
+	{Name: "yearly", Days: 365, PerYear: 1, Tolerance: 12},
+}
+
+const (
+	// recurringMinOccurrences is a minimal number of transactions to call them "series".
+	recurringMinOccurrences = 3
+	// recurringAmountTolerance is a relative deviation of an amount from the series median
+	// which is still treated as "the same amount".
+	recurringAmountTolerance = 0.25
+	// recurringPriceChangeThreshold is a relative difference between the last amount and
+	// the typical amount of the series to report price change.
+	recurringPriceChangeThreshold = 0.02
+	// recurringRegularIntervalsRatio is a minimal share of intervals which should match cadence.
+	recurringRegularIntervalsRatio = 0.75
+)
+
+// RecurringSeries is a list of similar transactions repeating with a regular cadence.
+type RecurringSeries struct {
+	Key            string
+	IsExpense      bool
+	Cadence        Cadence
+	Transactions   []Transaction
+	TypicalAmount  MoneyWith2DecimalPlaces
+	LastAmount     MoneyWith2DecimalPlaces
+	NextDate       time.Time
+	AnnualAmount   MoneyWith2DecimalPlaces
+	IsStopped      bool
+	IsPriceChanged bool
+}
+
+// ExcludeIgnoredTransactions returns transactions which are not ignored by rules of the statistic builder,
+// i.e. ones which are counted in the statistic.
+func ExcludeIgnoredTransactions(
+	transactions []Transaction,
+	groupExtractorFactory StatisticBuilderFactory,
+) []Transaction {
+	isIgnored := newIgnoreChecker(groupExtractorFactory)
+	result := make([]Transaction, 0, len(transactions))
+	for _, trans := range transactions {
+		if !isIgnored(trans) {
+			result = append(result, trans)
+		}
+	}
+	return result
+}
+
+// DetectRecurringSeries finds series of transactions with similar details and amount
+// repeating at regular intervals. `now` is a moment to check that series stopped,
+// usually the date of the latest known transaction.
+// Returns series sorted by annual amount descending.
+func DetectRecurringSeries(transactions []Transaction, now time.Time) []RecurringSeries {
+
+	// Split transactions by direction and normalized details.
+	type seriesKey struct {
+		isExpense bool
+		details   string
+	}
+	candidates := map[seriesKey][]Transaction{}
+	for _, t := range transactions {
+		key := seriesKey{t.IsExpense, normalizeDetails(t.Details)}
+		if key.details == "" {
+			continue
+		}
+		candidates[key] = append(candidates[key], t)
+	}
+
+	result := []RecurringSeries{}
+	for key, list := range candidates {
+		if len(list) < 2 {
+			continue
+		}
+		sorted := make([]Transaction, len(list))
+		copy(sorted, list)
+		sort.Stable(TransactionList(sorted))
+
+		series, ok := buildRecurringSeries(sorted, now)
+		if !ok {
+			continue
+		}
+		series.Key = key.details
+		series.IsExpense = key.isExpense
+		result = append(result, series)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].AnnualAmount.int != result[j].AnnualAmount.int {
+			return result[i].AnnualAmount.int > result[j].AnnualAmount.int
+		}
+		return result[i].Key < result[j].Key
+	})
+	return result
+}
+
+// buildRecurringSeries checks that sorted by date transactions form a regular series.
+func buildRecurringSeries(sorted []Transaction, now time.Time) (RecurringSeries, bool) {
+
+	// Find typical amount and check that most of amounts are close to it.
+	amounts := make([]int, len(sorted))
+	for i, t := range sorted {
+		amounts[i] = t.Amount.int
+	}
+	typical := medianInt(amounts)
+	if typical <= 0 {
+		return RecurringSeries{}, false
+	}
+	similar := 0
+	for _, a := range amounts {
+		if relativeDifference(a, typical) <= recurringAmountTolerance {
+			similar++
+		}
+	}
+	if float64(similar)/float64(len(amounts)) < recurringRegularIntervalsRatio {
+		return RecurringSeries{}, false
+	}
+
+	// Find cadence by median interval between transactions.
+	intervals := make([]int, 0, len(sorted)-1)
+	for i := 1; i < len(sorted); i++ {
+		intervals = append(intervals, int(sorted[i].Date.Sub(sorted[i-1].Date).Hours()/24+0.5))
+	}
+	medianInterval := medianInt(intervals)
+	var cadence *Cadence
+	for i, c := range cadences {
+		if medianInterval >= c.Days-c.Tolerance && medianInterval <= c.Days+c.Tolerance {
+			cadence = &cadences[i]
+			break
+		}
+	}
+	if cadence == nil {
+		return RecurringSeries{}, false
+	}
+	minOccurrences := recurringMinOccurrences
+	if cadence.PerYear == 1 {
+		minOccurrences = 2
+	}
+	if len(sorted) < minOccurrences {
+		return RecurringSeries{}, false
+	}
+	regular := 0
+	for _, interval := range intervals {
+		if interval >= cadence.Days-cadence.Tolerance && interval <= cadence.Days+cadence.Tolerance {
+			regular++
+		}
+	}
+	if float64(regular)/float64(len(intervals)) < recurringRegularIntervalsRatio {
+		return RecurringSeries{}, false
+	}
+
+	last := sorted[len(sorted)-1]
+	nextDate := cadence.addTo(last.Date, 1)
+	return RecurringSeries{
+		Cadence:        *cadence,
+		Transactions:   sorted,
+		TypicalAmount:  MoneyWith2DecimalPlaces{typical},
+		LastAmount:     last.Amount,
+		NextDate:       nextDate,
+		AnnualAmount:   MoneyWith2DecimalPlaces{last.Amount.int * cadence.PerYear},
+		IsStopped:      now.After(nextDate.AddDate(0, 0, cadence.Tolerance)),
+		IsPriceChanged: relativeDifference(last.Amount.int, typical) > recurringPriceChangeThreshold,
+	}, true
+}
+
+func (s RecurringSeries) String() string {
+	direction := "Income"
+	if s.IsExpense {
+		direction = "Expense"
+	}
+	notes := []string{}
+	if s.IsStopped {
+		notes = append(notes, fmt.Sprintf("STOPPED, expected on %s", s.NextDate.Format(OutputDateFormat)))
+	}
+	if s.IsPriceChanged {
+		notes = append(notes, fmt.Sprintf("PRICE CHANGED from %s", strings.TrimSpace(s.TypicalAmount.String())))
+	}
+	result := fmt.Sprintf("%-7s %-9s %-35s: %s, annual %s, next %s, %d times since %s",
+		direction,
+		s.Cadence.Name,
+		s.Key,
+		s.LastAmount,
+		s.AnnualAmount,
+		s.NextDate.Format(OutputDateFormat),
+		len(s.Transactions),
+		s.Transactions[0].Date.Format(OutputDateFormat),
+	)
+	if len(notes) > 0 {
+		result += " - " + strings.Join(notes, ", ")
+	}
+	return result
+}
+
+// RecurringSeriesToString converts list of `RecurringSeries` to human readable string.
+func RecurringSeriesToString(series []RecurringSeries) string {
+	lines := make([]string, len(series))
+	annualExpense, annualIncome := 0, 0
+	for i, s := range series {
+		lines[i] = "\n  " + s.String()
+		if s.IsStopped {
+			continue
+		}
+		if s.IsExpense {
+			annualExpense += s.AnnualAmount.int
+		} else {
+			annualIncome += s.AnnualAmount.int
+		}
+	}
+	return fmt.Sprintf(
+		"\nRecurring transactions (%d series, active annual income=%s, expense=%s):%s",
+		len(series),
+		MoneyWith2DecimalPlaces{annualIncome},
+		MoneyWith2DecimalPlaces{annualExpense},
+		strings.Join(lines, ""),
+	)
+}
+
+func medianInt(values []int) int {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := make([]int, len(values))
+	copy(sorted, values)
+	sort.Ints(sorted)
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return sorted[middle]
+}
+
+func relativeDifference(value, base int) float64 {
+	if base == 0 {
+		return 0
+	}
+	diff := float64(value - base)
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff / float64(base)
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func newRecurringT(date time.Time, amount int, isExpense bool, details string) Transaction {
+	return Transaction{
+		IsExpense: isExpense,
+		Date:      date,
+		Details:   details,
+		Amount:    MoneyWith2DecimalPlaces{amount},
+	}
+}
+
+func monthlyTransactions(start time.Time, count int, amount int, details string) []Transaction {
+	result := make([]Transaction, count)
+	for i := 0; i < count; i++ {
+		result[i] = newRecurringT(start.AddDate(0, i, 0), amount, true, details)
+	}
+	return result
+}
+
+func TestDetectRecurringSeries(t *testing.T) {
+	start := time.Date(2023, time.January, 5, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name                string
+		transactions        []Transaction
+		now                 time.Time
+		expectedCount       int
+		expectedCadence     string
+		expectedKey         string
+		expectedAnnual      int
+		expectedNextDate    time.Time
+		expectedStopped     bool
+		expectedPriceChange bool
+	}{
+		{
+			name:             "monthly_with_changing_order_numbers",
+			transactions:     append(monthlyTransactions(start, 3, 1000, "GOOGLE *YouTube 123"), monthlyTransactions(start.AddDate(0, 3, 0), 3, 1000, "GOOGLE *YouTube 456")...),
+			now:              start.AddDate(0, 5, 0),
+			expectedCount:    1,
+			expectedCadence:  "monthly",
+			expectedKey:      "GOOGLE YOUTUBE",
+			expectedAnnual:   12000,
+			expectedNextDate: start.AddDate(0, 6, 0),
+		},
+		{
+			name:             "stopped",
+			transactions:     monthlyTransactions(start, 4, 500, "CLOUD"),
+			now:              start.AddDate(0, 8, 0),
+			expectedCount:    1,
+			expectedCadence:  "monthly",
+			expectedKey:      "CLOUD",
+			expectedAnnual:   6000,
+			expectedNextDate: start.AddDate(0, 4, 0),
+			expectedStopped:  true,
+		},
+		{
+			name: "price_changed",
+			transactions: append(monthlyTransactions(start, 4, 1000, "NETFLIX"),
+				newRecurringT(start.AddDate(0, 4, 0), 1200, true, "NETFLIX")),
+			now:                 start.AddDate(0, 4, 0),
+			expectedCount:       1,
+			expectedCadence:     "monthly",
+			expectedKey:         "NETFLIX",
+			expectedAnnual:      14400,
+			expectedNextDate:    start.AddDate(0, 5, 0),
+			expectedPriceChange: true,
+		},
+		{
+			name: "weekly",
+			transactions: []Transaction{
+				newRecurringT(start, 300, true, "GYM"),
+				newRecurringT(start.AddDate(0, 0, 7), 300, true, "GYM"),
+				newRecurringT(start.AddDate(0, 0, 14), 300, true, "GYM"),
+				newRecurringT(start.AddDate(0, 0, 21), 300, true, "GYM"),
+			},
+			now:              start.AddDate(0, 0, 21),
+			expectedCount:    1,
+			expectedCadence:  "weekly",
+			expectedKey:      "GYM",
+			expectedAnnual:   300 * 52,
+			expectedNextDate: start.AddDate(0, 0, 28),
+		},
+		{
+			name: "yearly_from_2_transactions",
+			transactions: []Transaction{
+				newRecurringT(start, 5000, true, "DOMAIN RENEWAL"),
+				newRecurringT(start.AddDate(1, 0, 0), 5000, true, "DOMAIN RENEWAL"),
+			},
+			now:              start.AddDate(1, 0, 0),
+			expectedCount:    1,
+			expectedCadence:  "yearly",
+			expectedKey:      "DOMAIN RENEWAL",
+			expectedAnnual:   5000,
+			expectedNextDate: start.AddDate(2, 0, 0),
+		},
+		{
+			name: "irregular",
+			transactions: []Transaction{
+				newRecurringT(start, 1000, true, "MARKET"),
+				newRecurringT(start.AddDate(0, 0, 3), 1000, true, "MARKET"),
+				newRecurringT(start.AddDate(0, 0, 40), 1000, true, "MARKET"),
+				newRecurringT(start.AddDate(0, 0, 41), 1000, true, "MARKET"),
+			},
+			now:           start.AddDate(0, 2, 0),
+			expectedCount: 0,
+		},
+		{
+			name: "different_amounts",
+			transactions: []Transaction{
+				newRecurringT(start, 1000, true, "MARKET"),
+				newRecurringT(start.AddDate(0, 1, 0), 5000, true, "MARKET"),
+				newRecurringT(start.AddDate(0, 2, 0), 200, true, "MARKET"),
+				newRecurringT(start.AddDate(0, 3, 0), 9000, true, "MARKET"),
+			},
+			now:           start.AddDate(0, 3, 0),
+			expectedCount: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// Act
+			actual := DetectRecurringSeries(tt.transactions, tt.now)
+
+			// Assert
+			if len(actual) != tt.expectedCount {
+				t.Fatalf("expected %d series, got %d: %+v", tt.expectedCount, len(actual), actual)
+			}
+			if tt.expectedCount == 0 {
+				return
+			}
+			s := actual[0]
+			if s.Cadence.Name != tt.expectedCadence {
+				t.Errorf("expected cadence %s, got %s", tt.expectedCadence, s.Cadence.Name)
+			}
+			if s.Key != tt.expectedKey {
+				t.Errorf("expected key '%s', got '%s'", tt.expectedKey, s.Key)
+			}
+			if s.AnnualAmount.int != tt.expectedAnnual {
+				t.Errorf("expected annual amount %d, got %d", tt.expectedAnnual, s.AnnualAmount.int)
+			}
+			if !s.NextDate.Equal(tt.expectedNextDate) {
+				t.Errorf("expected next date %v, got %v", tt.expectedNextDate, s.NextDate)
+			}
+			if s.IsStopped != tt.expectedStopped {
+				t.Errorf("expected IsStopped=%v, got %v", tt.expectedStopped, s.IsStopped)
+			}
+			if s.IsPriceChanged != tt.expectedPriceChange {
+				t.Errorf("expected IsPriceChanged=%v, got %v", tt.expectedPriceChange, s.IsPriceChanged)
+			}
+		})
+	}
+}
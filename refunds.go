@@ -0,0 +1,108 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// RefundsGroupName is a name of the income group with refunds which are not netted against expenses.
+const RefundsGroupName = "refunds"
+
+// defaultRefundMaxDays is a default number of days after expense when refund for it is expected.
+const defaultRefundMaxDays = 60
+
+// refundableExpense is an expense which may be refunded.
+type refundableExpense struct {
+	transaction *Transaction
+	// remaining is an amount which is not refunded yet.
+	remaining int
+}
+
+// DetectRefunds marks incomes which return money of expenses as refunds. Income is a refund if
+// its details contain one of `Refunds.Substrings` or, with `Refunds.MatchExpenses` setting, if it
+// is not bigger than not refunded part of some previous expense of the same account with the same
+// merchant made not earlier than `Refunds.MaxDays` days ago. In the last case `RefundedDetails`
+// is set to details of the expense to put refund into the same group. Transactions ignored by rules
+// of the statistic builder are neither refunds nor refunded expenses. Returns number of refunds.
+func DetectRefunds(transactions []Transaction, settings Refunds, groupExtractorFactory StatisticBuilderFactory) int {
+	if !settings.MatchExpenses && len(settings.Substrings) == 0 {
+		return 0
+	}
+	maxDays := int(settings.MaxDays)
+	if maxDays == 0 {
+		maxDays = defaultRefundMaxDays
+	}
+
+	// Walk through transactions in chronological order keeping expenses which may be refunded.
+	indexes := make([]int, len(transactions))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return transactions[indexes[i]].Date.Before(transactions[indexes[j]].Date)
+	})
+	isIgnored := newIgnoreChecker(groupExtractorFactory)
+	expenses := []*refundableExpense{}
+	count := 0
+	for _, i := range indexes {
+		t := &transactions[i]
+		if isIgnored(*t) {
+			continue
+		}
+		if t.IsExpense {
+			if settings.MatchExpenses {
+				expenses = append(expenses, &refundableExpense{transaction: t, remaining: t.Amount.int})
+			}
+			continue
+		}
+		if settings.MatchExpenses {
+			if expense := findRefundedExpense(expenses, *t, t.Date.AddDate(0, 0, -maxDays)); expense != nil {
+				expense.remaining -= t.Amount.int
+				t.IsRefund = true
+				t.RefundedDetails = expense.transaction.Details
+				count++
+				continue
+			}
+		}
+		for _, substring := range settings.Substrings {
+			if strings.Contains(t.Details, substring) {
+				t.IsRefund = true
+				count++
+				break
+			}
+		}
+	}
+	return count
+}
+
+// findRefundedExpense returns the latest expense made after `since` which may be refunded by
+// the income or nil if there are no such.
+func findRefundedExpense(expenses []*refundableExpense, income Transaction, since time.Time) *refundableExpense {
+	merchant := normalizeDetails(income.Details)
+	if merchant == "" {
+		return nil
+	}
+	for i := len(expenses) - 1; i >= 0; i-- {
+		expense := expenses[i]
+		if expense.transaction.Date.Before(since) {
+			break
+		}
+		if expense.transaction.Account != income.Account || expense.remaining < income.Amount.int {
+			continue
+		}
+		if isSameMerchant(merchant, normalizeDetails(expense.transaction.Details)) {
+			return expense
+		}
+	}
+	return nil
+}
+
+// isSameMerchant checks that normalized details are equal or one of them contains all words
+// of another one, like "WILDBERRIES" and "REFUND WILDBERRIES".
+func isSameMerchant(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+	return strings.Contains(" "+a+" ", " "+b+" ") || strings.Contains(" "+b+" ", " "+a+" ")
+}
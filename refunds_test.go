@@ -0,0 +1,229 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestDetectRefunds(t *testing.T) {
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	newT := func(days int, amount int, isExpense bool, details string) Transaction {
+		return Transaction{
+			IsExpense: isExpense,
+			Date:      day.AddDate(0, 0, days),
+			Details:   details,
+			Account:   "card",
+			Amount:    MoneyWith2DecimalPlaces{amount},
+		}
+	}
+	tests := []struct {
+		name                    string
+		transactions            []Transaction
+		settings                Refunds
+		expectedRefunds         []bool
+		expectedRefundedDetails []string
+	}{
+		{
+			"disabled",
+			[]Transaction{newT(0, 5000, true, "WILDBERRIES"), newT(3, 5000, false, "WILDBERRIES")},
+			Refunds{},
+			[]bool{false, false},
+			[]string{"", ""},
+		},
+		{
+			"matched_by_merchant",
+			[]Transaction{
+				newT(0, 5000, true, "WILDBERRIES MOSCOW"),
+				newT(1, 3000, true, "YANDEX GO"),
+				newT(3, 2000, false, "REFUND WILDBERRIES MOSCOW"),
+				newT(4, 3000, false, "WILDBERRIES MOSCOW"),
+				newT(5, 1000, false, "SALARY"),
+			},
+			Refunds{MatchExpenses: true},
+			[]bool{false, false, true, true, false},
+			[]string{"", "", "WILDBERRIES MOSCOW", "WILDBERRIES MOSCOW", ""},
+		},
+		{
+			"bigger_than_expense",
+			[]Transaction{newT(0, 5000, true, "WILDBERRIES"), newT(3, 6000, false, "WILDBERRIES")},
+			Refunds{MatchExpenses: true},
+			[]bool{false, false},
+			[]string{"", ""},
+		},
+		{
+			"too_late",
+			[]Transaction{newT(0, 5000, true, "WILDBERRIES"), newT(10, 5000, false, "WILDBERRIES")},
+			Refunds{MatchExpenses: true, MaxDays: 7},
+			[]bool{false, false},
+			[]string{"", ""},
+		},
+		{
+			"before_expense",
+			[]Transaction{newT(3, 5000, false, "WILDBERRIES"), newT(0, 5000, true, "WILDBERRIES")},
+			Refunds{MatchExpenses: true},
+			[]bool{true, false},
+			[]string{"WILDBERRIES", ""},
+		},
+		{
+			"by_substring",
+			[]Transaction{newT(0, 5000, false, "RETURN OF GOODS"), newT(1, 5000, false, "SALARY")},
+			Refunds{Substrings: []string{"RETURN"}},
+			[]bool{true, false},
+			[]string{"", ""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// Arrange
+			factory, err := NewStatisticBuilderByDetailsSubstrings(&Config{GroupAllUnknownTransactions: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Act
+			count := DetectRefunds(tt.transactions, tt.settings, factory)
+
+			// Assert
+			expectedCount := 0
+			for i, transaction := range tt.transactions {
+				if tt.expectedRefunds[i] {
+					expectedCount++
+				}
+				if transaction.IsRefund != tt.expectedRefunds[i] {
+					t.Errorf("transaction %d IsRefund = %v, want %v", i, transaction.IsRefund, tt.expectedRefunds[i])
+				}
+				if transaction.RefundedDetails != tt.expectedRefundedDetails[i] {
+					t.Errorf("transaction %d RefundedDetails = %q, want %q",
+						i, transaction.RefundedDetails, tt.expectedRefundedDetails[i])
+				}
+			}
+			if count != expectedCount {
+				t.Errorf("DetectRefunds() = %d, want %d", count, expectedCount)
+			}
+		})
+	}
+}
+
+func TestDetectRefunds_Ignored(t *testing.T) {
+	// Arrange. Transfer to deposit and back is ignored, so it is neither refunded expense nor refund.
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	transactions := []Transaction{
+		{IsExpense: true, Date: day, Details: "DEPOSIT TRANSFER", Account: "card", Amount: MoneyWith2DecimalPlaces{5000}},
+		{Date: day.AddDate(0, 0, 3), Details: "DEPOSIT TRANSFER", Account: "card", Amount: MoneyWith2DecimalPlaces{5000}},
+		{Date: day.AddDate(0, 0, 4), Details: "RETURN DEPOSIT", Account: "card", Amount: MoneyWith2DecimalPlaces{100}},
+	}
+	factory, err := NewStatisticBuilderByDetailsSubstrings(&Config{
+		GroupAllUnknownTransactions: true,
+		IgnoreSubstrings:            []string{"DEPOSIT"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	count := DetectRefunds(transactions, Refunds{MatchExpenses: true, Substrings: []string{"RETURN"}}, factory)
+
+	// Assert
+	if count != 0 {
+		t.Errorf("DetectRefunds() = %d, want 0", count)
+	}
+	for i, transaction := range transactions {
+		if transaction.IsRefund {
+			t.Errorf("transaction %d is detected as refund", i)
+		}
+	}
+}
+
+func TestHandleTransaction_Refunds(t *testing.T) {
+	expense := Transaction{IsExpense: true, Date: now, Details: "WILDBERRIES", Amount: MoneyWith2DecimalPlaces{5000}}
+	refund := Transaction{Date: now, Details: "REFUND WB", Amount: MoneyWith2DecimalPlaces{2000},
+		IsRefund: true, RefundedDetails: "WILDBERRIES"}
+	nettedRefund := refund
+	nettedRefund.IsExpense = true
+	tests := []struct {
+		name            string
+		isNetRefunds    bool
+		expectedExpense map[string]*Group
+		expectedIncome  map[string]*Group
+	}{
+		{
+			"gross",
+			false,
+			map[string]*Group{"Marketplaces": groupFromITs("Marketplaces", []Transaction{expense})},
+			map[string]*Group{RefundsGroupName: groupFromITs(RefundsGroupName, []Transaction{refund})},
+		},
+		{
+			"net",
+			true,
+			map[string]*Group{"Marketplaces": {
+				Name:         "Marketplaces",
+				Total:        MoneyWith2DecimalPlaces{3000},
+				Refunds:      MoneyWith2DecimalPlaces{2000},
+				Transactions: []Transaction{expense, nettedRefund},
+			}},
+			map[string]*Group{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			builder, err := NewStatisticBuilderByDetailsSubstrings(&Config{
+				GroupNamesToSubstrings:      map[string][]string{"Marketplaces": {"WILDBERRIES"}},
+				GroupAllUnknownTransactions: true,
+				Refunds:                     Refunds{Net: tt.isNetRefunds},
+			})
+			if err != nil {
+				t.Fatalf("NewStatisticBuilderByDetailsSubstrings() failed: %#v", err)
+			}
+			handler := builder(now, nowPlusMonth)
+
+			// Act
+			for _, trans := range []Transaction{expense, refund} {
+				if err := handler.HandleTransaction(trans); err != nil {
+					t.Errorf("HandleTransaction() failed on %v with %#v", trans, err)
+				}
+			}
+
+			// Assert
+			expected := newIntervalStatistic()
+			expected.Expense = tt.expectedExpense
+			expected.Income = tt.expectedIncome
+			assertIntervalStatisticEqual(expected, handler.GetIntervalStatistic(), t)
+		})
+	}
+}
+
+func TestHandleTransaction_IgnoredRefund(t *testing.T) {
+	refund := Transaction{Date: now, Details: "DEPOSIT TRANSFER", Amount: MoneyWith2DecimalPlaces{2000},
+		IsRefund: true, RefundedDetails: "WILDBERRIES"}
+	for _, isNetRefunds := range []bool{false, true} {
+		t.Run(fmt.Sprintf("net=%v", isNetRefunds), func(t *testing.T) {
+			// Arrange
+			builder, err := NewStatisticBuilderByDetailsSubstrings(&Config{
+				GroupNamesToSubstrings:      map[string][]string{"Marketplaces": {"WILDBERRIES"}},
+				GroupAllUnknownTransactions: true,
+				IgnoreSubstrings:            []string{"DEPOSIT"},
+				Refunds:                     Refunds{Net: isNetRefunds},
+			})
+			if err != nil {
+				t.Fatalf("NewStatisticBuilderByDetailsSubstrings() failed: %#v", err)
+			}
+			handler := builder(now, nowPlusMonth)
+
+			// Act
+			err = handler.HandleTransaction(refund)
+
+			// Assert
+			if err != nil {
+				t.Errorf("HandleTransaction() failed on %v with %#v", refund, err)
+			}
+			expected := newIntervalStatistic()
+			expected.Ignored = map[string]*Group{
+				"ignoreSubstrings \"DEPOSIT\"": groupFromITs("ignoreSubstrings \"DEPOSIT\"", []Transaction{refund}),
+			}
+			assertIntervalStatisticEqual(expected, handler.GetIntervalStatistic(), t)
+		})
+	}
+}
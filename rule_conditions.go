@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Directions of transactions for `RuleConditions.Direction`.
+const (
+	DirectionIncome  = "income"
+	DirectionExpense = "expense"
+)
+
+// ruleConditionsDateFormat is a format of dates in `RuleConditions`.
+const ruleConditionsDateFormat = "2006-01-02"
+
+// ruleConditions are compiled `RuleConditions`. Transaction matches if it satisfies all conditions.
+type ruleConditions struct {
+	substring string
+	regexp    *regexp.Regexp
+	direction string
+	minAmount *MoneyWith2DecimalPlaces
+	maxAmount *MoneyWith2DecimalPlaces
+	accounts  map[string]bool
+	// from and to are inclusive dates in `ruleConditionsDateFormat`, empty if not limited.
+	from string
+	to   string
+	// label is a human readable list of conditions.
+	label string
+}
+
+// newRuleConditions checks and compiles `RuleConditions` from configuration.
+// Returns error if there are no conditions because such rule would match all transactions.
+func newRuleConditions(c RuleConditions) (ruleConditions, error) {
+	result := ruleConditions{
+		substring: c.Substring,
+		direction: c.Direction,
+		minAmount: c.MinAmount,
+		maxAmount: c.MaxAmount,
+		from:      c.From,
+		to:        c.To,
+	}
+	if c.Regexp != "" {
+		compiled, err := regexp.Compile(c.Regexp)
+		if err != nil {
+			return ruleConditions{}, fmt.Errorf("wrong regular expression '%s': %w", c.Regexp, err)
+		}
+		result.regexp = compiled
+	}
+	if len(c.Accounts) > 0 {
+		result.accounts = map[string]bool{}
+		for _, account := range c.Accounts {
+			result.accounts[account] = true
+		}
+	}
+	if c.MinAmount != nil && c.MaxAmount != nil && c.MinAmount.int > c.MaxAmount.int {
+		return ruleConditions{}, fmt.Errorf("minAmount %s is bigger than maxAmount %s",
+			strings.TrimSpace(c.MinAmount.String()), strings.TrimSpace(c.MaxAmount.String()))
+	}
+	if c.From != "" && c.To != "" && c.From > c.To {
+		return ruleConditions{}, fmt.Errorf("'from' date %s is after 'to' date %s", c.From, c.To)
+	}
+	conditions := c.conditions()
+	if len(conditions) == 0 {
+		return ruleConditions{}, fmt.Errorf("rule doesn't have conditions and would match all transactions")
+	}
+	result.label = strings.Join(conditions, ", ")
+	return result, nil
+}
+
+// conditions returns human readable list of conditions.
+func (c RuleConditions) conditions() []string {
+	conditions := []string{}
+	if c.Substring != "" {
+		conditions = append(conditions, fmt.Sprintf("substring %q", c.Substring))
+	}
+	if c.Regexp != "" {
+		conditions = append(conditions, fmt.Sprintf("regexp %q", c.Regexp))
+	}
+	if c.Direction != "" {
+		conditions = append(conditions, c.Direction)
+	}
+	if c.MinAmount != nil || c.MaxAmount != nil {
+		amounts := [2]string{}
+		for i, amount := range []*MoneyWith2DecimalPlaces{c.MinAmount, c.MaxAmount} {
+			if amount != nil {
+				amounts[i] = strings.TrimSpace(amount.String())
+			}
+		}
+		conditions = append(conditions, fmt.Sprintf("amount %s..%s", amounts[0], amounts[1]))
+	}
+	if len(c.Accounts) > 0 {
+		conditions = append(conditions, fmt.Sprintf("accounts %v", c.Accounts))
+	}
+	if c.From != "" || c.To != "" {
+		conditions = append(conditions, fmt.Sprintf("dates %s..%s", c.From, c.To))
+	}
+	return conditions
+}
+
+// matches checks that transaction satisfies all conditions.
+func (c ruleConditions) matches(trans Transaction) bool {
+	if c.substring != "" && !strings.Contains(trans.Details, c.substring) {
+		return false
+	}
+	if c.regexp != nil && !c.regexp.MatchString(trans.Details) {
+		return false
+	}
+	if (c.direction == DirectionExpense && !trans.IsExpense) || (c.direction == DirectionIncome && trans.IsExpense) {
+		return false
+	}
+	if (c.minAmount != nil && trans.Amount.int < c.minAmount.int) ||
+		(c.maxAmount != nil && trans.Amount.int > c.maxAmount.int) {
+		return false
+	}
+	if c.accounts != nil && !c.accounts[trans.Account] {
+		return false
+	}
+	date := trans.Date.Format(ruleConditionsDateFormat)
+	if (c.from != "" && date < c.from) || (c.to != "" && date > c.to) {
+		return false
+	}
+	return true
+}
+
+// ignoreRule is a compiled `IgnoreRule`.
+type ignoreRule struct {
+	ruleConditions
+	// label is a name of the rule or list of its conditions.
+	label string
+}
+
+// newIgnoreRule checks and compiles `IgnoreRule` from configuration.
+func newIgnoreRule(rule IgnoreRule) (ignoreRule, error) {
+	conditions, err := newRuleConditions(rule.RuleConditions)
+	if err != nil {
+		return ignoreRule{}, err
+	}
+	label := rule.Name
+	if label == "" {
+		label = conditions.label
+	}
+	return ignoreRule{conditions, label}, nil
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRuleConditions_Matches(t *testing.T) {
+	day := time.Date(2024, 3, 15, 23, 30, 0, 0, time.UTC)
+	transaction := Transaction{
+		IsExpense: true,
+		Date:      day,
+		Details:   "Transfer to 1234 card",
+		Account:   "card",
+		Amount:    MoneyWith2DecimalPlaces{50000},
+	}
+	amount := func(value int) *MoneyWith2DecimalPlaces {
+		return &MoneyWith2DecimalPlaces{value}
+	}
+	tests := []struct {
+		name     string
+		rule     RuleConditions
+		expected bool
+	}{
+		{"substring", RuleConditions{Substring: "Transfer"}, true},
+		{"substring_other", RuleConditions{Substring: "Salary"}, false},
+		{"regexp", RuleConditions{Regexp: `^Transfer to \d+`}, true},
+		{"regexp_other", RuleConditions{Regexp: `^\d+`}, false},
+		{"direction", RuleConditions{Direction: DirectionExpense}, true},
+		{"direction_other", RuleConditions{Direction: DirectionIncome}, false},
+		{"amount_range", RuleConditions{MinAmount: amount(50000), MaxAmount: amount(50000)}, true},
+		{"amount_less", RuleConditions{MinAmount: amount(50001)}, false},
+		{"amount_bigger", RuleConditions{MaxAmount: amount(49999)}, false},
+		{"accounts", RuleConditions{Accounts: []string{"other", "card"}}, true},
+		{"accounts_other", RuleConditions{Accounts: []string{"other"}}, false},
+		{"dates_inclusive", RuleConditions{From: "2024-03-15", To: "2024-03-15"}, true},
+		{"dates_before", RuleConditions{To: "2024-03-14"}, false},
+		{"dates_after", RuleConditions{From: "2024-03-16"}, false},
+		{"all_conditions", RuleConditions{
+			Substring: "Transfer",
+			Direction: DirectionExpense,
+			MaxAmount: amount(100000),
+			Accounts:  []string{"card"},
+			From:      "2024-01-01",
+		}, true},
+		{"one_condition_fails", RuleConditions{Substring: "Transfer", Direction: DirectionIncome}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			rule, err := newRuleConditions(tt.rule)
+			if err != nil {
+				t.Fatalf("newRuleConditions() failed: %v", err)
+			}
+
+			// Act
+			actual := rule.matches(transaction)
+
+			// Assert
+			if actual != tt.expected {
+				t.Errorf("matches() = %v, want %v", actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNewIgnoreRule(t *testing.T) {
+	tests := []struct {
+		name          string
+		rule          IgnoreRule
+		expectedLabel string
+		expectedError string
+	}{
+		{"name", IgnoreRule{Name: "Own transfers", RuleConditions: RuleConditions{Substring: "Transfer"}}, "Own transfers", ""},
+		{
+			"generated_label",
+			IgnoreRule{RuleConditions: RuleConditions{
+				Substring: "Transfer",
+				Direction: DirectionIncome,
+				MinAmount: &MoneyWith2DecimalPlaces{10000000},
+				Accounts:  []string{"card"},
+				To:        "2024-01-31",
+			}},
+			`substring "Transfer", income, amount 1,000.00.., accounts [card], dates ..2024-01-31`,
+			"",
+		},
+		{"no_conditions", IgnoreRule{Name: "All"}, "", "doesn't have conditions"},
+		{"wrong_regexp", IgnoreRule{RuleConditions: RuleConditions{Regexp: "("}}, "", "wrong regular expression"},
+		{
+			"wrong_amounts",
+			IgnoreRule{RuleConditions: RuleConditions{MinAmount: &MoneyWith2DecimalPlaces{2}, MaxAmount: &MoneyWith2DecimalPlaces{1}}},
+			"",
+			"is bigger than maxAmount",
+		},
+		{"wrong_dates", IgnoreRule{RuleConditions: RuleConditions{From: "2024-02-01", To: "2024-01-01"}}, "", "is after 'to' date"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// Act
+			rule, err := newIgnoreRule(tt.rule)
+
+			// Assert
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Errorf("newIgnoreRule() error = %v, want error with %q", err, tt.expectedError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("newIgnoreRule() failed: %v", err)
+			}
+			if rule.label != tt.expectedLabel {
+				t.Errorf("label = %q, want %q", rule.label, tt.expectedLabel)
+			}
+		})
+	}
+}
+
+func TestHandleTransaction_IgnoreRules(t *testing.T) {
+	// Arrange
+	ownTransfer := Transaction{IsExpense: true, Date: now, Details: "Transfer to own card", Amount: MoneyWith2DecimalPlaces{30000}}
+	friendTransfer := Transaction{IsExpense: true, Date: now, Details: "Transfer to Ann", Amount: MoneyWith2DecimalPlaces{500}}
+	cashback := Transaction{Date: now, Details: "Cashback", Amount: MoneyWith2DecimalPlaces{100}}
+	builder, err := NewStatisticBuilderByDetailsSubstrings(&Config{
+		GroupNamesToSubstrings:      map[string][]string{"Transfers": {"Transfer"}},
+		GroupAllUnknownTransactions: true,
+		IgnoreSubstrings:            []string{"Cashback"},
+		IgnoreRules: []IgnoreRule{{
+			Name:           "Own transfers",
+			RuleConditions: RuleConditions{Substring: "Transfer", MinAmount: &MoneyWith2DecimalPlaces{10000}},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("NewStatisticBuilderByDetailsSubstrings() failed: %#v", err)
+	}
+	handler := builder(now, nowPlusMonth)
+
+	// Act
+	for _, trans := range []Transaction{ownTransfer, friendTransfer, cashback} {
+		if err := handler.HandleTransaction(trans); err != nil {
+			t.Errorf("HandleTransaction() failed on %v with %#v", trans, err)
+		}
+	}
+
+	// Assert
+	expected := newIntervalStatistic()
+	expected.Expense = map[string]*Group{"Transfers": groupFromITs("Transfers", []Transaction{friendTransfer})}
+	expected.Ignored = map[string]*Group{
+		`ignoreRules "Own transfers"`: groupFromITs(`ignoreRules "Own transfers"`, []Transaction{ownTransfer}),
+		`ignoreSubstrings "Cashback"`: groupFromITs(`ignoreSubstrings "Cashback"`, []Transaction{cashback}),
+	}
+	actual := handler.GetIntervalStatistic()
+	assertIntervalStatisticEqual(expected, actual, t)
+	ignored := IgnoredToString(actual, false)
+	if !strings.Contains(ignored, "Ignored (2, income sum=0.01, expense sum=3.00)") {
+		t.Errorf("IgnoredToString() = %q doesn't contain sums", ignored)
+	}
+}
+
+func TestBuildMonthlyStatistic_LastIntervalOnlyIgnored(t *testing.T) {
+	// Arrange
+	start := time.Date(2024, time.January, 10, 0, 0, 0, 0, time.UTC)
+	transactions := []Transaction{
+		{IsExpense: true, Date: start, Details: "Market", Amount: MoneyWith2DecimalPlaces{100}},
+		{IsExpense: true, Date: start.AddDate(0, 1, 0), Details: "Transfer to own card", Amount: MoneyWith2DecimalPlaces{200}},
+	}
+	factory, err := NewStatisticBuilderByDetailsSubstrings(&Config{
+		GroupAllUnknownTransactions: true,
+		IgnoreSubstrings:            []string{"Transfer"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	statistics, err := BuildMonthlyStatistic(transactions, factory, 1, time.UTC)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("BuildMonthlyStatistic() failed: %v", err)
+	}
+	if len(statistics) != 2 {
+		t.Fatalf("Expected 2 intervals, got %d", len(statistics))
+	}
+	if len(statistics[1].Ignored) != 1 {
+		t.Errorf("Expected ignored transaction in the last interval, got %v", statistics[1].Ignored)
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// splitPart is a compiled `SplitPart`.
+type splitPart struct {
+	group   string
+	percent float64
+	amount  *MoneyWith2DecimalPlaces
+}
+
+// isRest checks that part gets the rest of the amount.
+func (p splitPart) isRest() bool {
+	return p.percent == 0 && p.amount == nil
+}
+
+// splitPortion is an amount of split transaction assigned to the group.
+type splitPortion struct {
+	group  string
+	amount int
+}
+
+// splitRule is a compiled `SplitRule`. Parts with percents or fixed amounts are taken in order of
+// configuration, each one is limited by not distributed yet amount. The rest goes to the part without
+// share or, if there is no such part, to the group chosen for the whole transaction by other rules.
+type splitRule struct {
+	ruleConditions
+	// label is a name of the rule or list of its conditions.
+	label string
+	parts []splitPart
+}
+
+// newSplitRule checks and compiles `SplitRule` from configuration.
+func newSplitRule(rule SplitRule) (splitRule, error) {
+	conditions, err := newRuleConditions(rule.RuleConditions)
+	if err != nil {
+		return splitRule{}, err
+	}
+	label := rule.Name
+	if label == "" {
+		label = conditions.label
+	}
+	if len(rule.Parts) == 0 {
+		return splitRule{}, fmt.Errorf("rule doesn't have parts")
+	}
+	parts := make([]splitPart, 0, len(rule.Parts))
+	restGroup := ""
+	percents := 0.0
+	for _, part := range rule.Parts {
+		if part.Group == "" {
+			return splitRule{}, fmt.Errorf("part doesn't have group")
+		}
+		if part.Percent < 0 || part.Percent > 100 {
+			return splitRule{}, fmt.Errorf("percent %v of '%s' part is out of 0..100 range", part.Percent, part.Group)
+		}
+		if part.Percent > 0 && part.Amount != nil {
+			return splitRule{}, fmt.Errorf("'%s' part has both percent and amount", part.Group)
+		}
+		if part.Amount != nil && part.Amount.int <= 0 {
+			return splitRule{}, fmt.Errorf("amount %s of '%s' part should be positive",
+				strings.TrimSpace(part.Amount.String()), part.Group)
+		}
+		compiled := splitPart{group: part.Group, percent: part.Percent, amount: part.Amount}
+		if compiled.isRest() {
+			if restGroup != "" {
+				return splitRule{}, fmt.Errorf("both '%s' and '%s' parts get the rest, only one may be without share",
+					restGroup, part.Group)
+			}
+			restGroup = part.Group
+		}
+		percents += part.Percent
+		parts = append(parts, compiled)
+	}
+	if percents > 100 {
+		return splitRule{}, fmt.Errorf("sum of percents %v is more than 100", percents)
+	}
+	return splitRule{conditions, label, parts}, nil
+}
+
+// split divides amount into portions of groups. Portions with zero amount are skipped.
+// Returns not distributed rest which should go to the group chosen by other rules.
+func (r splitRule) split(amount int) (portions []splitPortion, rest int) {
+	rest = amount
+	restGroup := ""
+	for _, part := range r.parts {
+		if part.isRest() {
+			restGroup = part.group
+			continue
+		}
+		value := 0
+		if part.amount != nil {
+			value = part.amount.int
+		} else {
+			value = int(math.Round(float64(amount) * part.percent / 100))
+		}
+		if value > rest {
+			value = rest
+		}
+		if value <= 0 {
+			continue
+		}
+		portions = append(portions, splitPortion{part.group, value})
+		rest -= value
+	}
+	if restGroup != "" && rest > 0 {
+		portions = append(portions, splitPortion{restGroup, rest})
+		rest = 0
+	}
+	return portions, rest
+}
+
+// splitPortion returns copy of transaction with only portion of its amount.
+// Amount spent on manual transactions is expected to be already excluded from the portion.
+func (t Transaction) splitPortion(amount int) Transaction {
+	if t.SplitOf.int == 0 {
+		t.SplitOf = t.Amount
+	}
+	t.Amount = MoneyWith2DecimalPlaces{amount}
+	t.Allocated = MoneyWith2DecimalPlaces{}
+	return t
+}
+
+// unsplit returns transaction with the whole amount if it is a portion of split transaction.
+func (t Transaction) unsplit() Transaction {
+	if t.SplitOf.int > 0 {
+		t.Amount = t.SplitOf
+		t.SplitOf = MoneyWith2DecimalPlaces{}
+	}
+	return t
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewSplitRule(t *testing.T) {
+	amount := func(value int) *MoneyWith2DecimalPlaces {
+		return &MoneyWith2DecimalPlaces{value}
+	}
+	conditions := RuleConditions{Substring: "SUPERMARKET"}
+	tests := []struct {
+		name          string
+		rule          SplitRule
+		expectedError string
+		expectedLabel string
+	}{
+		{
+			"name_is_label",
+			SplitRule{Name: "Receipts", RuleConditions: conditions, Parts: []SplitPart{{Group: "Household", Percent: 30}}},
+			"",
+			"Receipts",
+		},
+		{
+			"conditions_are_label",
+			SplitRule{RuleConditions: conditions, Parts: []SplitPart{{Group: "Household", Amount: amount(100)}}},
+			"",
+			`substring "SUPERMARKET"`,
+		},
+		{
+			"no_conditions",
+			SplitRule{Parts: []SplitPart{{Group: "Household", Percent: 30}}},
+			"doesn't have conditions",
+			"",
+		},
+		{
+			"no_parts",
+			SplitRule{RuleConditions: conditions},
+			"doesn't have parts",
+			"",
+		},
+		{
+			"part_without_group",
+			SplitRule{RuleConditions: conditions, Parts: []SplitPart{{Percent: 30}}},
+			"part doesn't have group",
+			"",
+		},
+		{
+			"percent_and_amount",
+			SplitRule{RuleConditions: conditions, Parts: []SplitPart{{Group: "Household", Percent: 30, Amount: amount(100)}}},
+			"has both percent and amount",
+			"",
+		},
+		{
+			"not_positive_amount",
+			SplitRule{RuleConditions: conditions, Parts: []SplitPart{{Group: "Household", Amount: amount(0)}}},
+			"should be positive",
+			"",
+		},
+		{
+			"two_rest_parts",
+			SplitRule{RuleConditions: conditions, Parts: []SplitPart{{Group: "Groceries"}, {Group: "Household"}}},
+			"only one may be without share",
+			"",
+		},
+		{
+			"percents_over_100",
+			SplitRule{RuleConditions: conditions, Parts: []SplitPart{
+				{Group: "Groceries", Percent: 70},
+				{Group: "Household", Percent: 40},
+			}},
+			"more than 100",
+			"",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			rule, err := newSplitRule(tt.rule)
+
+			// Assert
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Errorf("newSplitRule() error = %v, want containing %q", err, tt.expectedError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("newSplitRule() failed: %v", err)
+			}
+			if rule.label != tt.expectedLabel {
+				t.Errorf("label = %q, want %q", rule.label, tt.expectedLabel)
+			}
+		})
+	}
+}
+
+func TestSplitRule_Split(t *testing.T) {
+	amount := func(value int) *MoneyWith2DecimalPlaces {
+		return &MoneyWith2DecimalPlaces{value}
+	}
+	tests := []struct {
+		name             string
+		parts            []SplitPart
+		amount           int
+		expectedPortions []splitPortion
+		expectedRest     int
+	}{
+		{
+			"percents",
+			[]SplitPart{{Group: "Groceries", Percent: 70}, {Group: "Household", Percent: 30}},
+			1000,
+			[]splitPortion{{"Groceries", 700}, {"Household", 300}},
+			0,
+		},
+		{
+			"percent_is_rounded",
+			[]SplitPart{{Group: "Household", Percent: 33.3}},
+			1001,
+			[]splitPortion{{"Household", 333}},
+			668,
+		},
+		{
+			"amount_and_rest",
+			[]SplitPart{{Group: "Utilities"}, {Group: "Rent", Amount: amount(5000000)}},
+			6500000,
+			[]splitPortion{{"Rent", 5000000}, {"Utilities", 1500000}},
+			0,
+		},
+		{
+			"amount_is_limited",
+			[]SplitPart{{Group: "Rent", Amount: amount(5000000)}, {Group: "Utilities", Amount: amount(100)}},
+			4000000,
+			[]splitPortion{{"Rent", 4000000}},
+			0,
+		},
+		{
+			"zero_amount",
+			[]SplitPart{{Group: "Household", Percent: 30}, {Group: "Groceries"}},
+			0,
+			nil,
+			0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			rule, err := newSplitRule(SplitRule{RuleConditions: RuleConditions{Substring: "A"}, Parts: tt.parts})
+			if err != nil {
+				t.Fatalf("newSplitRule() failed: %v", err)
+			}
+
+			// Act
+			portions, rest := rule.split(tt.amount)
+
+			// Assert
+			if !reflect.DeepEqual(portions, tt.expectedPortions) {
+				t.Errorf("portions = %v, want %v", portions, tt.expectedPortions)
+			}
+			if rest != tt.expectedRest {
+				t.Errorf("rest = %d, want %d", rest, tt.expectedRest)
+			}
+		})
+	}
+}
+
+func TestHandleTransaction_SplitRules(t *testing.T) {
+	// Arrange
+	receipt := Transaction{IsExpense: true, Date: now, Details: "SUPERMARKET", Amount: MoneyWith2DecimalPlaces{10000}}
+	transfer := Transaction{IsExpense: true, Date: now, Details: "Transfer to landlord", Amount: MoneyWith2DecimalPlaces{6500000}}
+	refund := Transaction{Date: now, Details: "SUPERMARKET", Amount: MoneyWith2DecimalPlaces{1000}, IsRefund: true}
+	builder, err := NewStatisticBuilderByDetailsSubstrings(&Config{
+		GroupNamesToSubstrings:      map[string][]string{"Groceries": {"SUPERMARKET"}, "Utilities": {"landlord"}},
+		GroupAllUnknownTransactions: true,
+		SplitRules: []SplitRule{
+			{
+				Name:           "Supermarket receipts",
+				RuleConditions: RuleConditions{Substring: "SUPERMARKET"},
+				Parts:          []SplitPart{{Group: "Household", Percent: 30}},
+			},
+			{
+				RuleConditions: RuleConditions{Substring: "landlord", From: "2024-01-01"},
+				Parts:          []SplitPart{{Group: "Rent", Amount: &MoneyWith2DecimalPlaces{5000000}}},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewStatisticBuilderByDetailsSubstrings() failed: %#v", err)
+	}
+	handler := builder(now, nowPlusMonth)
+
+	// Act
+	for _, trans := range []Transaction{receipt, transfer, refund} {
+		if err := handler.HandleTransaction(trans); err != nil {
+			t.Errorf("HandleTransaction() failed on %v with %#v", trans, err)
+		}
+	}
+
+	// Assert
+	portion := func(trans Transaction, amount int) Transaction {
+		trans.SplitOf = trans.Amount
+		trans.Amount = MoneyWith2DecimalPlaces{amount}
+		return trans
+	}
+	expected := newIntervalStatistic()
+	expected.Expense = map[string]*Group{
+		"Household": groupFromITs("Household", []Transaction{portion(receipt, 3000)}),
+		"Groceries": groupFromITs("Groceries", []Transaction{portion(receipt, 7000)}),
+		"Rent":      groupFromITs("Rent", []Transaction{portion(transfer, 5000000)}),
+		"Utilities": groupFromITs("Utilities", []Transaction{portion(transfer, 1500000)}),
+	}
+	expected.Income = map[string]*Group{RefundsGroupName: groupFromITs(RefundsGroupName, []Transaction{refund})}
+	actual := handler.GetIntervalStatistic()
+	assertIntervalStatisticEqual(expected, actual, t)
+	household := actual.Expense["Household"].Transactions[0]
+	if !strings.Contains(household.String(), "(part of 1.00)") {
+		t.Errorf("String() = %q doesn't mention the whole amount", household.String())
+	}
+}
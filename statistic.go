@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
@@ -26,17 +27,49 @@ import (
 // }
 
 func (t *Transaction) String() string {
-	return fmt.Sprintf("Transaction %s %s %s", t.Date.Format(OutputDateFormat), t.Amount, t.Details)
+	kind := "Transaction"
+	if t.IsRefund {
+		kind = "Refund"
+	}
+	notes := []string{}
+	if t.IsCash {
+		notes = append(notes, "cash")
+	}
+	if t.Allocated.int > 0 {
+		notes = append(notes, fmt.Sprintf("%s spent on manual transactions", strings.TrimSpace(t.Allocated.String())))
+	}
+	if t.SplitOf.int > 0 {
+		notes = append(notes, fmt.Sprintf("part of %s", strings.TrimSpace(t.SplitOf.String())))
+	}
+	if t.Balance != nil {
+		notes = append(notes, fmt.Sprintf("balance %s", strings.TrimSpace(t.Balance.String())))
+	}
+	if len(notes) > 0 {
+		return fmt.Sprintf("%s %s %s %s (%s)",
+			kind, t.Date.Format(OutputDateFormat), t.Amount, t.Details, strings.Join(notes, ", "))
+	}
+	return fmt.Sprintf("%s %s %s %s", kind, t.Date.Format(OutputDateFormat), t.Amount, t.Details)
 }
 
 func (m MoneyWith2DecimalPlaces) String() string {
-	dollars := m.int / 100
-	cents := m.int % 100
+	value := changePrecision(m.int, amountsPrecision, outputPrecision)
+	sign := ""
+	if value < 0 {
+		sign = "-"
+		value = -value
+	}
+	divisor := pow10(outputPrecision)
+	dollars := value / divisor
+	cents := value % divisor
 	dollarString := strconv.Itoa(dollars)
 	for i := len(dollarString) - 3; i > 0; i -= 3 {
 		dollarString = dollarString[:i] + "," + dollarString[i:]
 	}
-	return fmt.Sprintf("%9s.%02d", dollarString, cents)
+	dollarString = sign + dollarString
+	if outputPrecision == 0 {
+		return fmt.Sprintf("%9s", dollarString)
+	}
+	return fmt.Sprintf("%9s.%0*d", dollarString, outputPrecision, cents)
 }
 
 // GroupList structure to sort groups by `TotalAmount2DigitAfterDot` descending.
@@ -82,6 +115,16 @@ func MapOfGroupsToStringFull(mapOfGroups map[string]*Group, withTransactions boo
 
 	groupStrings := []string{}
 	for _, group := range groupList {
+		name := group.Name
+		if group.Confidence > 0 {
+			name = fmt.Sprintf("%s ~%.0f%%", name, group.Confidence*100)
+		}
+		total := group.Total.String()
+		if group.Refunds.int > 0 {
+			total = fmt.Sprintf("%s (gross %s, refunds %s)", total,
+				strings.TrimSpace(MoneyWith2DecimalPlaces{group.Total.int + group.Refunds.int}.String()),
+				strings.TrimSpace(group.Refunds.String()))
+		}
 		if withTransactions {
 			transStrings := make([]string, len(group.Transactions))
 			for j, t := range group.Transactions {
@@ -90,8 +133,8 @@ func MapOfGroupsToStringFull(mapOfGroups map[string]*Group, withTransactions boo
 			groupStrings = append(groupStrings,
 				fmt.Sprintf(
 					"\n    %-35s: %s, from %d transaction(s):\n      %s",
-					group.Name,
-					group.Total,
+					name,
+					total,
 					len(transStrings),
 					strings.Join(transStrings, "\n      "),
 				),
@@ -100,8 +143,8 @@ func MapOfGroupsToStringFull(mapOfGroups map[string]*Group, withTransactions boo
 			groupStrings = append(groupStrings,
 				fmt.Sprintf(
 					"\n    %-35s: %s",
-					group.Name,
-					group.Total,
+					name,
+					total,
 				),
 			)
 		}
@@ -117,7 +160,7 @@ func MapOfGroupsToString(mapOfGroups map[string]*Group) []string {
 func (s *IntervalStatistic) String() string {
 	income := MapOfGroupsToStringFull(s.Income, true)
 	expense := MapOfGroupsToStringFull(s.Expense, true)
-	return fmt.Sprintf("Statistics for %s..%s:\n  Income (%d, sum=%s):%s\n  Expenses (%d, sum=%s):%s\n",
+	return fmt.Sprintf("Statistics for %s..%s:\n  Income (%d, sum=%s):%s\n  Expenses (%d, sum=%s):%s%s%s\n",
 		s.Start.Format(OutputDateFormat),
 		s.End.Format(OutputDateFormat),
 		len(income),
@@ -126,6 +169,36 @@ func (s *IntervalStatistic) String() string {
 		len(s.Expense),
 		MapOfGroupsSum(s.Expense),
 		strings.Join(expense, ""),
+		IgnoredToString(s, true),
+		BalancesToString(s.Balances),
+	)
+}
+
+// IgnoredToString returns section with transactions excluded by ignore rules, grouped by rule,
+// or empty string if nothing was ignored. Sums of incomes and expenses are shown separately.
+func IgnoredToString(s *IntervalStatistic, withTransactions bool) string {
+	if len(s.Ignored) == 0 {
+		return ""
+	}
+	income, expense := MoneyWith2DecimalPlaces{}, MoneyWith2DecimalPlaces{}
+	for _, group := range s.Ignored {
+		for _, trans := range group.Transactions {
+			switch {
+			case trans.isNettedRefund():
+				expense.int -= trans.Amount.int
+			case trans.IsExpense:
+				expense.int += trans.Amount.int
+			default:
+				income.int += trans.Amount.int
+			}
+		}
+	}
+	ignored := MapOfGroupsToStringFull(s.Ignored, withTransactions)
+	return fmt.Sprintf("\n  Ignored (%d, income sum=%s, expense sum=%s):%s",
+		len(ignored),
+		strings.TrimSpace(income.String()),
+		strings.TrimSpace(expense.String()),
+		strings.Join(ignored, ""),
 	)
 }
 
@@ -151,19 +224,170 @@ type IntervalStatisticsBuilder interface {
 
 const UnknownGroupName = "unknown"
 
+// groupRegexp is a regular expression to search in `Transaction.Details` for some group.
+type groupRegexp struct {
+	regexp    *regexp.Regexp
+	groupName string
+}
+
+// accountRules is a set of rules applied only to transactions of specific accounts.
+type accountRules struct {
+	accounts              map[string]bool
+	substringsToGroupName map[string]string
+	ignoreSubstrings      []string
+}
+
 // groupExtractorByDetailsSubstrings is [main.IntervalStatisticsBuilder] which uses
 // `Transaction.Details` field to choose right group. Logic is following:
 //  1. Find is group for expenses of incomes.
-//  2. Search group in `substringsToGroupName` field. If there are such then update it.
-//  3. Otherwise check isGroupAllUnknown value:
-//  4. If `false` then create new group with name equal to `Transaction.Details` field
-//  5. If `true` then add into single group with name from `UnknownGroupName` constant.
+//  2. Search group in `substringsToGroupName` of `accountRules` for `Transaction.Account`.
+//  3. Search group in `substringsToGroupName` field. If there are such then update it.
+//     If many substrings match then the longest one wins.
+//  4. Search group in `regexpsToGroupName` field. If there are such then update it.
+//  5. Otherwise check isGroupAllUnknown value:
+//  6. If `false` then create new group with name equal to `Transaction.Details` field
+//  7. If `true` then add into single group with name from `UnknownGroupName` constant.
 type groupExtractorByDetailsSubstrings struct {
 	intervalStats          *IntervalStatistic
 	groupNamesToSubstrings map[string][]string
 	substringsToGroupName  map[string]string
+	regexpsToGroupName     []groupRegexp
+	accountRules           []accountRules
 	isGroupAllUnknown      bool
 	ignoreSubstrings       []string
+	ignoreRules            []ignoreRule
+	splitRules             []splitRule
+	isNetRefunds           bool
+}
+
+// RuleMatch is a rule from configuration which matches some transaction.
+type RuleMatch struct {
+	// Section is a name of configuration section with the rule.
+	Section   string
+	Pattern   string
+	GroupName string
+	IsIgnore  bool
+}
+
+func (m RuleMatch) String() string {
+	if m.IsIgnore {
+		return fmt.Sprintf("%s %q", m.Section, m.Pattern)
+	}
+	return fmt.Sprintf("%s %q of '%s' group", m.Section, m.Pattern, m.GroupName)
+}
+
+// sortMatchedSubstrings sorts matched substrings from the longest (i.e. the most specific)
+// to the shortest one to make choice of group independent from order in configuration.
+func sortMatchedSubstrings(matches []RuleMatch) {
+	sort.SliceStable(matches, func(i, j int) bool {
+		if len(matches[i].Pattern) != len(matches[j].Pattern) {
+			return len(matches[i].Pattern) > len(matches[j].Pattern)
+		}
+		return matches[i].Pattern < matches[j].Pattern
+	})
+}
+
+// matchRules returns all rules matching transaction in order of priority, i.e. the first one wins:
+//  1. `ignoreSubstrings`, both common and account specific, and `ignoreRules`.
+//  2. Account specific `groupNamesToSubstrings`, from the longest substring.
+//  3. Common `groupNamesToSubstrings`, from the longest substring.
+//  4. `groupNamesToRegexps` in order of group names.
+func (s groupExtractorByDetailsSubstrings) matchRules(trans Transaction) []RuleMatch {
+	matches := []RuleMatch{}
+
+	// Find rules specific for the account of transaction.
+	var scopedRules []accountRules
+	for _, rules := range s.accountRules {
+		if rules.accounts[trans.Account] {
+			scopedRules = append(scopedRules, rules)
+		}
+	}
+
+	// Ignore rules.
+	for _, substring := range s.ignoreSubstrings {
+		if strings.Contains(trans.Details, substring) {
+			matches = append(matches, RuleMatch{Section: "ignoreSubstrings", Pattern: substring, IsIgnore: true})
+		}
+	}
+	for _, rules := range scopedRules {
+		for _, substring := range rules.ignoreSubstrings {
+			if strings.Contains(trans.Details, substring) {
+				matches = append(matches, RuleMatch{
+					Section:  "accountRules.ignoreSubstrings",
+					Pattern:  substring,
+					IsIgnore: true,
+				})
+			}
+		}
+	}
+	for _, rule := range s.ignoreRules {
+		if rule.matches(trans) {
+			matches = append(matches, RuleMatch{Section: "ignoreRules", Pattern: rule.label, IsIgnore: true})
+		}
+	}
+
+	// Group rules. Account specific rules have priority.
+	scopedMatches := []RuleMatch{}
+	for _, rules := range scopedRules {
+		for substring, groupName := range rules.substringsToGroupName {
+			if strings.Contains(trans.Details, substring) {
+				scopedMatches = append(scopedMatches, RuleMatch{
+					Section:   "accountRules.groupNamesToSubstrings",
+					Pattern:   substring,
+					GroupName: groupName,
+				})
+			}
+		}
+	}
+	sortMatchedSubstrings(scopedMatches)
+	matches = append(matches, scopedMatches...)
+	commonMatches := []RuleMatch{}
+	for substring, groupName := range s.substringsToGroupName {
+		if strings.Contains(trans.Details, substring) {
+			commonMatches = append(commonMatches, RuleMatch{
+				Section:   "groupNamesToSubstrings",
+				Pattern:   substring,
+				GroupName: groupName,
+			})
+		}
+	}
+	sortMatchedSubstrings(commonMatches)
+	matches = append(matches, commonMatches...)
+	for _, r := range s.regexpsToGroupName {
+		if r.regexp.MatchString(trans.Details) {
+			matches = append(matches, RuleMatch{
+				Section:   "groupNamesToRegexps",
+				Pattern:   r.regexp.String(),
+				GroupName: r.groupName,
+			})
+		}
+	}
+	return matches
+}
+
+// matchIgnoreRule returns the rule ignoring transaction or nil if transaction is counted in the statistic.
+// Group chosen manually wins over rules.
+func (s groupExtractorByDetailsSubstrings) matchIgnoreRule(trans Transaction) *RuleMatch {
+	if trans.Group != "" {
+		return nil
+	}
+	matches := s.matchRules(trans)
+	if len(matches) == 0 || !matches[0].IsIgnore {
+		return nil
+	}
+	return &matches[0]
+}
+
+// newIgnoreChecker returns function which checks that transaction is ignored by rules of the statistic builder.
+// Without rules nothing is ignored.
+func newIgnoreChecker(groupExtractorFactory StatisticBuilderFactory) func(Transaction) bool {
+	extractor, ok := groupExtractorFactory(time.Time{}, time.Time{}).(groupExtractorByDetailsSubstrings)
+	if !ok {
+		return func(Transaction) bool { return false }
+	}
+	return func(trans Transaction) bool {
+		return extractor.matchIgnoreRule(trans) != nil
+	}
 }
 
 func (s groupExtractorByDetailsSubstrings) HandleTransaction(trans Transaction) error {
@@ -176,77 +400,113 @@ func (s groupExtractorByDetailsSubstrings) HandleTransaction(trans Transaction)
 		mapOfGroups = s.intervalStats.Income
 	}
 
-	// First check that need to ignore transaction.
-	for _, substring := range s.ignoreSubstrings {
-		if strings.Contains(trans.Details, substring) {
+	// Ignore rules are checked before refunds to not count ignored transfers as refunds.
+	if match := s.matchIgnoreRule(trans); match != nil {
+		addTransactionToGroup(s.intervalStats.Ignored, match.String(), trans)
+		return nil
+	}
+
+	// Refunds either decrease group of the refunded expense or go into separate income group.
+	details := trans.Details
+	if trans.IsRefund {
+		if !s.isNetRefunds {
+			addTransactionToGroup(mapOfGroups, RefundsGroupName, trans)
 			return nil
 		}
+		trans, details = asNettedRefund(trans)
+		mapOfGroups = s.intervalStats.Expense
 	}
 
-	// Try to find user-defined group in configuration and add transaction to it.
-	found := false
-	for substring, groupName := range s.substringsToGroupName {
-		if strings.Contains(trans.Details, substring) {
-			group, exists := mapOfGroups[groupName]
-			if !exists {
-				// If the group doesn't exist in the map, create a new one.
-				group = &Group{
-					Name:         groupName,
-					Total:        MoneyWith2DecimalPlaces{},
-					Transactions: []Transaction{},
-				}
-				mapOfGroups[groupName] = group
-			}
-			group.Transactions = append(group.Transactions, trans)
-			group.Total.int += trans.Amount.int
-			found = true
-			break
-		}
+	// Group chosen manually wins over rules.
+	if trans.Group != "" {
+		addTransactionToGroup(mapOfGroups, trans.Group, trans)
+		return nil
 	}
 
-	// Otherwise add transaction to either "unknown" or personal group.
-	if !found {
-		// Choose name of group to add transaction into.
-		var groupName string
-		if s.isGroupAllUnknown {
-			groupName = UnknownGroupName
-		} else {
-			groupName = trans.Details
-		}
+	// Check rules. The first matched rule either ignores transaction or chooses group.
+	ruleTrans := trans
+	ruleTrans.Details = details
+	matches := s.matchRules(ruleTrans)
+	if len(matches) > 0 && matches[0].IsIgnore {
+		addTransactionToGroup(s.intervalStats.Ignored, matches[0].String(), trans)
+		return nil
+	}
 
-		// Check group exists or create a new one. Add transaction to group.
-		if group, exists := mapOfGroups[groupName]; exists {
-			group.Total.int += trans.Amount.int
-			group.Transactions = append(group.Transactions, trans)
-		} else {
-			newGroup := Group{
-				Name:         groupName,
-				Total:        trans.Amount,
-				Transactions: []Transaction{trans},
+	// Otherwise use group of the rule or either "unknown" or personal group.
+	groupName := details
+	if len(matches) > 0 {
+		groupName = matches[0].GroupName
+	} else if s.isGroupAllUnknown {
+		groupName = UnknownGroupName
+	}
+
+	// Split rule moves portions of transaction into own groups, the rest stays in the chosen group.
+	if rule := s.matchSplitRule(trans); rule != nil {
+		portions, rest := rule.split(trans.groupAmount())
+		if len(portions) > 0 {
+			for _, portion := range portions {
+				addTransactionToGroup(mapOfGroups, portion.group, trans.splitPortion(portion.amount))
 			}
-			mapOfGroups[newGroup.Name] = &newGroup
+			if rest == 0 {
+				return nil
+			}
+			trans = trans.splitPortion(rest)
 		}
 	}
+	addTransactionToGroup(mapOfGroups, groupName, trans)
+	return nil
+}
 
+// matchSplitRule returns the first split rule matching transaction or nil. Refunds are not split.
+func (s groupExtractorByDetailsSubstrings) matchSplitRule(trans Transaction) *splitRule {
+	if trans.IsRefund {
+		return nil
+	}
+	for i := range s.splitRules {
+		if s.splitRules[i].matches(trans) {
+			return &s.splitRules[i]
+		}
+	}
 	return nil
 }
 
+// asNettedRefund returns refund as it is put into expense groups to decrease them and details
+// to choose the group by, i.e. details of the refunded expense if it is known.
+func asNettedRefund(trans Transaction) (Transaction, string) {
+	trans.IsExpense = true
+	if trans.RefundedDetails != "" {
+		return trans, trans.RefundedDetails
+	}
+	return trans, trans.Details
+}
+
+// addTransactionToGroup adds transaction to the group with specified name, creates group if need.
+func addTransactionToGroup(mapOfGroups map[string]*Group, groupName string, trans Transaction) {
+	group, exists := mapOfGroups[groupName]
+	if !exists {
+		group = &Group{
+			Name:         groupName,
+			Total:        MoneyWith2DecimalPlaces{},
+			Transactions: []Transaction{},
+		}
+		mapOfGroups[groupName] = group
+	}
+	group.Transactions = append(group.Transactions, trans)
+	group.Total.int += trans.groupAmount()
+	if trans.isNettedRefund() {
+		group.Refunds.int += trans.Amount.int
+	}
+}
+
 func (s groupExtractorByDetailsSubstrings) GetIntervalStatistic() *IntervalStatistic {
 	return s.intervalStats
 }
 
 type StatisticBuilderFactory func(start, end time.Time) IntervalStatisticsBuilder
 
-// NewStatisticBuilderByDetailsSubstrings returns
-// [github.com/AlexanderMakarov/aggregate-inecobank-statement.main.GroupExtractorBuilder] which builds
-// [github.com/AlexanderMakarov/aggregate-inecobank-statement.main.groupExtractorByDetailsSubstrings] in a safe way.
-func NewStatisticBuilderByDetailsSubstrings(
-	groupNamesToSubstrings map[string][]string,
-	isGroupAllUnknownTransactions bool,
-	ignoreSubstrings []string,
-) (StatisticBuilderFactory, error) {
-
-	// Invert groupNamesToSubstrings and check for duplicates.
+// invertGroupNamesToSubstrings converts map of group names to substrings into map of substring
+// to group name. Returns error if some substring is used for multiple groups.
+func invertGroupNamesToSubstrings(groupNamesToSubstrings map[string][]string) (map[string]string, error) {
 	substringsToGroupName := map[string]string{}
 	for name, substrings := range groupNamesToSubstrings {
 		for _, substring := range substrings {
@@ -257,9 +517,85 @@ func NewStatisticBuilderByDetailsSubstrings(
 			substringsToGroupName[substring] = name
 		}
 	}
+	return substringsToGroupName, nil
+}
+
+// NewStatisticBuilderByDetailsSubstrings returns
+// [github.com/AlexanderMakarov/aggregate-inecobank-statement.main.GroupExtractorBuilder] which builds
+// [github.com/AlexanderMakarov/aggregate-inecobank-statement.main.groupExtractorByDetailsSubstrings] in a safe way
+// from rules of the configuration. Classifier needs all not matched transactions in one group so
+// `Config.ClassifyUnknownTransactions` works as `Config.GroupAllUnknownTransactions`.
+func NewStatisticBuilderByDetailsSubstrings(config *Config) (StatisticBuilderFactory, error) {
+	groupNamesToSubstrings := config.GroupNamesToSubstrings
+	groupNamesToRegexps := config.GroupNamesToRegexps
+	isGroupAllUnknownTransactions := config.GroupAllUnknownTransactions || config.ClassifyUnknownTransactions
+
+	// Invert groupNamesToSubstrings and check for duplicates.
+	substringsToGroupName, err := invertGroupNamesToSubstrings(groupNamesToSubstrings)
+	if err != nil {
+		return nil, err
+	}
 	log.Printf("Going to separate transactions by %d named groups from %d substrings",
 		len(groupNamesToSubstrings), len(substringsToGroupName))
 
+	// Compile regular expressions. Sort them to have stable order of checks.
+	groupNames := make([]string, 0, len(groupNamesToRegexps))
+	for name := range groupNamesToRegexps {
+		groupNames = append(groupNames, name)
+	}
+	sort.Strings(groupNames)
+	regexpsToGroupName := []groupRegexp{}
+	for _, name := range groupNames {
+		for _, expression := range groupNamesToRegexps[name] {
+			compiled, err := regexp.Compile(expression)
+			if err != nil {
+				return nil, fmt.Errorf("wrong regular expression '%s' in '%s' group: %w", expression, name, err)
+			}
+			regexpsToGroupName = append(regexpsToGroupName, groupRegexp{compiled, name})
+		}
+	}
+
+	// Compile ignore rules.
+	compiledIgnoreRules := make([]ignoreRule, 0, len(config.IgnoreRules))
+	for i, rule := range config.IgnoreRules {
+		compiled, err := newIgnoreRule(rule)
+		if err != nil {
+			return nil, fmt.Errorf("ignore rule #%d: %w", i+1, err)
+		}
+		compiledIgnoreRules = append(compiledIgnoreRules, compiled)
+	}
+	compiledSplitRules := make([]splitRule, 0, len(config.SplitRules))
+	for i, rule := range config.SplitRules {
+		compiled, err := newSplitRule(rule)
+		if err != nil {
+			return nil, fmt.Errorf("split rule #%d: %w", i+1, err)
+		}
+		compiledSplitRules = append(compiledSplitRules, compiled)
+	}
+
+	// The same for account specific rules.
+	scopedRules := make([]accountRules, 0, len(config.AccountRules))
+	for i, rules := range config.AccountRules {
+		if len(rules.Accounts) == 0 {
+			return nil, fmt.Errorf("%d account rules don't have accounts", i+1)
+		}
+		rulesSubstringsToGroupName, err := invertGroupNamesToSubstrings(rules.GroupNamesToSubstrings)
+		if err != nil {
+			return nil, fmt.Errorf("%d account rules for %v: %w", i+1, rules.Accounts, err)
+		}
+		accounts := map[string]bool{}
+		for _, account := range rules.Accounts {
+			accounts[account] = true
+		}
+		scopedRules = append(scopedRules, accountRules{
+			accounts:              accounts,
+			substringsToGroupName: rulesSubstringsToGroupName,
+			ignoreSubstrings:      rules.IgnoreSubstrings,
+		})
+		log.Printf("Going to separate transactions of %v accounts by %d named groups from %d substrings",
+			rules.Accounts, len(rules.GroupNamesToSubstrings), len(rulesSubstringsToGroupName))
+	}
+
 	return func(start, end time.Time) IntervalStatisticsBuilder {
 
 		// Return new groupExtractorByDetailsSubstrings.
@@ -269,15 +605,83 @@ func NewStatisticBuilderByDetailsSubstrings(
 				End:     end,
 				Income:  make(map[string]*Group),
 				Expense: make(map[string]*Group),
+				Ignored: make(map[string]*Group),
 			},
 			groupNamesToSubstrings: groupNamesToSubstrings,
 			substringsToGroupName:  substringsToGroupName,
+			regexpsToGroupName:     regexpsToGroupName,
+			accountRules:           scopedRules,
 			isGroupAllUnknown:      isGroupAllUnknownTransactions,
-			ignoreSubstrings:       ignoreSubstrings,
+			ignoreSubstrings:       config.IgnoreSubstrings,
+			ignoreRules:            compiledIgnoreRules,
+			splitRules:             compiledSplitRules,
+			isNetRefunds:           config.Refunds.Net,
 		}
 	}, nil
 }
 
+// SplitByAccounts splits `IntervalStatistic` into per-account statistics with the same groups.
+// Transactions without account are gathered under empty account name.
+func SplitByAccounts(s *IntervalStatistic) map[string]*IntervalStatistic {
+	result := map[string]*IntervalStatistic{}
+	getAccountStatistic := func(account string) *IntervalStatistic {
+		accountStatistic, exists := result[account]
+		if !exists {
+			accountStatistic = &IntervalStatistic{
+				Start:   s.Start,
+				End:     s.End,
+				Income:  make(map[string]*Group),
+				Expense: make(map[string]*Group),
+			}
+			result[account] = accountStatistic
+		}
+		return accountStatistic
+	}
+	for _, group := range s.Income {
+		for _, trans := range group.Transactions {
+			addTransactionToGroup(getAccountStatistic(trans.Account).Income, group.Name, trans)
+		}
+	}
+	for _, group := range s.Expense {
+		for _, trans := range group.Transactions {
+			addTransactionToGroup(getAccountStatistic(trans.Account).Expense, group.Name, trans)
+		}
+	}
+	return result
+}
+
+// AccountsBreakdownToString converts `IntervalStatistic` into human readable string with
+// incomes and expenses per each account.
+func AccountsBreakdownToString(s *IntervalStatistic) string {
+	byAccounts := SplitByAccounts(s)
+	accounts := make([]string, 0, len(byAccounts))
+	for account := range byAccounts {
+		accounts = append(accounts, account)
+	}
+	sort.Strings(accounts)
+	result := ""
+	for _, account := range accounts {
+		accountStatistic := byAccounts[account]
+		name := account
+		if name == "" {
+			name = "<unknown account>"
+		}
+		income := MapOfGroupsToString(accountStatistic.Income)
+		expense := MapOfGroupsToString(accountStatistic.Expense)
+		result += fmt.Sprintf(
+			"\n  Account %s:\n   Income (%d, sum=%s):%s\n   Expenses (%d, sum=%s):%s",
+			name,
+			len(income),
+			MapOfGroupsSum(accountStatistic.Income),
+			strings.Join(income, ""),
+			len(expense),
+			MapOfGroupsSum(accountStatistic.Expense),
+			strings.Join(expense, ""),
+		)
+	}
+	return result
+}
+
 // BuildMonthlyStatistic builds list of
 // [github.com/AlexanderMakarov/aggregate-inecobank-statement.main.IntervalStatistic]
 // per each month from provided transactions.
@@ -288,6 +692,10 @@ func BuildMonthlyStatistic(
 	timeLocation *time.Location,
 ) ([]*IntervalStatistic, error) {
 
+	if len(transactions) == 0 {
+		return nil, fmt.Errorf("there are no transactions to build statistic from")
+	}
+
 	// Sort transactions.
 	sort.Sort(TransactionList(transactions))
 
@@ -323,7 +731,7 @@ func BuildMonthlyStatistic(
 
 	// Add last IntervalStatistic if need.
 	lastStatistic := statBuilder.GetIntervalStatistic()
-	if len(lastStatistic.Expense) > 0 || len(lastStatistic.Income) > 0 {
+	if len(lastStatistic.Expense) > 0 || len(lastStatistic.Income) > 0 || len(lastStatistic.Ignored) > 0 {
 		stats = append(stats, lastStatistic)
 	}
 	return stats, nil
@@ -47,8 +47,10 @@ func Test_NewGroupExtractorByDetailsSubstrings(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 
 			// Act
-			builder, err := NewStatisticBuilderByDetailsSubstrings(tt.groupNamesToSubstrings,
-				tt.isGroupAllUnknownTransactions, []string{})
+			builder, err := NewStatisticBuilderByDetailsSubstrings(&Config{
+				GroupNamesToSubstrings:      tt.groupNamesToSubstrings,
+				GroupAllUnknownTransactions: tt.isGroupAllUnknownTransactions,
+			})
 			actualGE := builder(now, nowPlusMonth)
 
 			// Assert
@@ -234,6 +236,51 @@ func Test_groupExtractorByDetailsSubstrings_HandleTransaction(t *testing.T) {
 	}
 }
 
+func Test_groupExtractorByDetailsSubstrings_AccountRules(t *testing.T) {
+	// Arrange
+	personal := Transaction{IsExpense: true, Date: now, Details: "CLOUD", Amount: MoneyWith2DecimalPlaces{1}, Account: "personal"}
+	business := Transaction{IsExpense: true, Date: now, Details: "CLOUD", Amount: MoneyWith2DecimalPlaces{2}, Account: "business"}
+	transfer := Transaction{IsExpense: true, Date: now, Details: "TRANSFER", Amount: MoneyWith2DecimalPlaces{3}, Account: "business"}
+	builder, err := NewStatisticBuilderByDetailsSubstrings(&Config{
+		GroupNamesToSubstrings:      map[string][]string{"Subscriptions": {"CLOUD"}},
+		GroupAllUnknownTransactions: true,
+		AccountRules: []AccountRules{
+			{
+				Accounts:               []string{"business"},
+				IgnoreSubstrings:       []string{"TRANSFER"},
+				GroupNamesToSubstrings: map[string][]string{"Business": {"CLOUD"}},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewStatisticBuilderByDetailsSubstrings() failed: %#v", err)
+	}
+	handler := builder(now, nowPlusMonth)
+
+	// Act
+	for _, trans := range []Transaction{personal, business, transfer} {
+		if err := handler.HandleTransaction(trans); err != nil {
+			t.Errorf("HandleTransaction() failed on %v with %#v", trans, err)
+		}
+	}
+
+	// Assert
+	expected := newIntervalStatistic()
+	expected.Expense = map[string]*Group{
+		"Subscriptions": groupFromITs("Subscriptions", []Transaction{personal}),
+		"Business":      groupFromITs("Business", []Transaction{business}),
+	}
+	expected.Ignored = map[string]*Group{
+		`accountRules.ignoreSubstrings "TRANSFER"`: groupFromITs(`accountRules.ignoreSubstrings "TRANSFER"`, []Transaction{transfer}),
+	}
+	actual := handler.GetIntervalStatistic()
+	assertIntervalStatisticEqual(expected, actual, t)
+	byAccounts := SplitByAccounts(actual)
+	if len(byAccounts) != 2 || len(byAccounts["business"].Expense) != 1 || len(byAccounts["personal"].Expense) != 1 {
+		t.Errorf("SplitByAccounts() returned wrong result: %+v", byAccounts)
+	}
+}
+
 func assertIntervalStatisticEqual(expected, actual *IntervalStatistic, t *testing.T) {
 	if !expected.Start.Equal(actual.Start) {
 		t.Errorf("Start time does not match. Expected: %v, got: %v", expected.Start, actual.Start)
@@ -245,6 +292,7 @@ func assertIntervalStatisticEqual(expected, actual *IntervalStatistic, t *testin
 	}
 	compareGroupMap("Income", expected.Income, actual.Income, t)
 	compareGroupMap("Expense", expected.Expense, actual.Expense, t)
+	compareGroupMap("Ignored", expected.Ignored, actual.Ignored, t)
 }
 
 func compareGroupMap(name string, expected, actual map[string]*Group, t *testing.T) {
@@ -284,7 +332,7 @@ func equalGroup(a, b *Group) bool {
 	}
 
 	// Compare totals
-	if a.Total.int != b.Total.int {
+	if a.Total.int != b.Total.int || a.Refunds.int != b.Refunds.int {
 		return false
 	}
 
@@ -326,6 +374,7 @@ func newIntervalStatistic() *IntervalStatistic {
 		End:     nowPlusMonth,
 		Income:  make(map[string]*Group),
 		Expense: make(map[string]*Group),
+		Ignored: make(map[string]*Group),
 	}
 }
 
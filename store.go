@@ -0,0 +1,240 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// Types of lines in the store file.
+const (
+	storeLineFile        = "file"
+	storeLineTransaction = "transaction"
+	storeLineStatement   = "statement"
+)
+
+// StoredFile is a source file imported into `TransactionStore`.
+type StoredFile struct {
+	// Hash is SHA-256 of the file content, the same file is imported only once.
+	Hash       string    `json:"hash"`
+	Name       string    `json:"name"`
+	ImportedAt time.Time `json:"importedAt"`
+	// Transactions is a number of transactions added from the file, duplicates are not counted.
+	Transactions int `json:"transactions"`
+}
+
+// storeLine is a line of the store file. Exactly one of pointer fields is set according to `Type`.
+type storeLine struct {
+	Type        string            `json:"type"`
+	File        *StoredFile       `json:"file,omitempty"`
+	Hash        string            `json:"hash,omitempty"`
+	Transaction *Transaction      `json:"transaction,omitempty"`
+	Statement   *AccountStatement `json:"statement,omitempty"`
+}
+
+// storeKey identifies stored transaction to find duplicates in files with overlapping periods.
+type storeKey struct {
+	account   string
+	isExpense bool
+	date      int64
+	details   string
+	amount    int
+}
+
+func newStoreKey(t Transaction) storeKey {
+	return storeKey{t.Account, t.IsExpense, t.Date.UnixNano(), t.Details, t.Amount.int}
+}
+
+// TransactionStore is a local JSON-lines file with transactions and statements from all imported
+// files. Lines are only appended: transactions and statements of the file first, then the line about
+// the file itself. Lines of files without such line (e.g. interrupted import) are ignored on reading.
+type TransactionStore struct {
+	path         string
+	files        map[string]StoredFile
+	transactions []Transaction
+	statements   []AccountStatement
+	counts       map[storeKey]int
+}
+
+// OpenTransactionStore reads the store file. Not existing file means empty store.
+func OpenTransactionStore(path string) (*TransactionStore, error) {
+	store := &TransactionStore{
+		path:   path,
+		files:  map[string]StoredFile{},
+		counts: map[storeKey]int{},
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	transactionsByHash := map[string][]Transaction{}
+	statementsByHash := map[string][]AccountStatement{}
+	hashes := []string{}
+	reader := bufio.NewReader(file)
+	for lineNumber := 1; ; lineNumber++ {
+		data, err := reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if len(bytes.TrimSpace(data)) > 0 {
+			var line storeLine
+			if jsonErr := json.Unmarshal(data, &line); jsonErr != nil {
+				if err == io.EOF {
+					break // The last line is not finished because of interrupted import.
+				}
+				return nil, fmt.Errorf("'%s':%d: %w", path, lineNumber, jsonErr)
+			}
+			switch {
+			case line.Type == storeLineFile && line.File != nil:
+				store.files[line.File.Hash] = *line.File
+				hashes = append(hashes, line.File.Hash)
+			case line.Type == storeLineTransaction && line.Transaction != nil:
+				transactionsByHash[line.Hash] = append(transactionsByHash[line.Hash], *line.Transaction)
+			case line.Type == storeLineStatement && line.Statement != nil:
+				statementsByHash[line.Hash] = append(statementsByHash[line.Hash], *line.Statement)
+			default:
+				return nil, fmt.Errorf("'%s':%d: unknown '%s' line", path, lineNumber, line.Type)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+	}
+	for _, hash := range hashes {
+		store.add(transactionsByHash[hash], statementsByHash[hash])
+	}
+	return store, nil
+}
+
+// hashSourceFile returns SHA-256 of the file content.
+func hashSourceFile(file sourceFile) (string, error) {
+	hash := sha256.New()
+	if file.IsEntry {
+		hash.Write(file.Data)
+	} else {
+		f, err := os.Open(file.Name)
+		if err != nil {
+			return "", err
+		}
+		defer f.Close()
+		if _, err := io.Copy(hash, f); err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// ImportedFile returns imported file with the hash if there is such.
+func (s *TransactionStore) ImportedFile(hash string) (StoredFile, bool) {
+	file, ok := s.files[hash]
+	return file, ok
+}
+
+// Import appends transactions and statements of the file into the store skipping ones which are
+// already in the store from other files. Returns the file with number of added transactions.
+func (s *TransactionStore) Import(
+	name, hash string,
+	transactions []Transaction,
+	statements []AccountStatement,
+) (StoredFile, error) {
+	newTransactions, newStatements := s.filterNew(transactions, statements)
+	stored := StoredFile{Hash: hash, Name: name, ImportedAt: time.Now(), Transactions: len(newTransactions)}
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for i := range newTransactions {
+		if err := encoder.Encode(storeLine{Type: storeLineTransaction, Hash: hash, Transaction: &newTransactions[i]}); err != nil {
+			return stored, err
+		}
+	}
+	for i := range newStatements {
+		if err := encoder.Encode(storeLine{Type: storeLineStatement, Hash: hash, Statement: &newStatements[i]}); err != nil {
+			return stored, err
+		}
+	}
+	if err := encoder.Encode(storeLine{Type: storeLineFile, File: &stored}); err != nil {
+		return stored, err
+	}
+	file, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return stored, err
+	}
+	if _, err := file.Write(buf.Bytes()); err != nil {
+		file.Close()
+		return stored, err
+	}
+	if err := file.Close(); err != nil {
+		return stored, err
+	}
+	s.files[hash] = stored
+	s.add(newTransactions, newStatements)
+	return stored, nil
+}
+
+// filterNew returns transactions and statements which are not in the store yet. The same
+// transactions may be in one file a few times, so only extra occurrences are treated as new.
+func (s *TransactionStore) filterNew(
+	transactions []Transaction,
+	statements []AccountStatement,
+) ([]Transaction, []AccountStatement) {
+	newTransactions := []Transaction{}
+	occurrences := map[storeKey]int{}
+	for _, t := range transactions {
+		key := newStoreKey(t)
+		occurrences[key]++
+		if occurrences[key] > s.counts[key] {
+			newTransactions = append(newTransactions, t)
+		}
+	}
+	newStatements := []AccountStatement{}
+	for _, statement := range statements {
+		if !s.hasStatement(statement) {
+			newStatements = append(newStatements, statement)
+		}
+	}
+	return newTransactions, newStatements
+}
+
+// hasStatement checks that the store has statement of the same account for the same period.
+func (s *TransactionStore) hasStatement(statement AccountStatement) bool {
+	for _, stored := range s.statements {
+		if stored.Account == statement.Account && stored.Start.Equal(statement.Start) && stored.End.Equal(statement.End) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *TransactionStore) add(transactions []Transaction, statements []AccountStatement) {
+	for _, t := range transactions {
+		s.counts[newStoreKey(t)]++
+	}
+	s.transactions = append(s.transactions, transactions...)
+	s.statements = append(s.statements, statements...)
+}
+
+// Transactions returns copy of all stored transactions.
+func (s *TransactionStore) Transactions() []Transaction {
+	return append([]Transaction{}, s.transactions...)
+}
+
+// Statements returns copy of all stored statements.
+func (s *TransactionStore) Statements() []AccountStatement {
+	return append([]AccountStatement{}, s.statements...)
+}
+
+// FilesCount returns number of imported files.
+func (s *TransactionStore) FilesCount() int {
+	return len(s.files)
+}
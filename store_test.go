@@ -0,0 +1,172 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTransactionStore_Import(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "store.jsonl")
+	day := time.Date(2024, 3, 1, 12, 0, 0, 0, time.FixedZone("AMT", 4*60*60))
+	newT := func(days int, amount int, details string) Transaction {
+		return Transaction{
+			IsExpense: true,
+			Date:      day.AddDate(0, 0, days),
+			Details:   details,
+			Amount:    MoneyWith2DecimalPlaces{amount},
+			Account:   "card",
+			Currency:  "AMD",
+			Source:    "'file' by InecoXmlParser",
+		}
+	}
+	coffee := newT(0, 150000, "COFFEE")
+	march := []Transaction{coffee, coffee, newT(1, 500000, "MARKET")}
+	overlapping := []Transaction{coffee, coffee, coffee, newT(1, 500000, "MARKET"), newT(40, 100, "TAXI")}
+	statement := AccountStatement{
+		Account:        "card",
+		Start:          day,
+		End:            day.AddDate(0, 1, 0),
+		OpeningBalance: MoneyWith2DecimalPlaces{-100},
+	}
+	store, err := OpenTransactionStore(path)
+	if err != nil {
+		t.Fatalf("OpenTransactionStore() failed: %v", err)
+	}
+
+	// Act
+	first, err := store.Import("march.xml", "hash1", march, []AccountStatement{statement})
+	if err != nil {
+		t.Fatalf("Import() failed: %v", err)
+	}
+	second, err := store.Import("march-april.xml", "hash2", overlapping, []AccountStatement{statement})
+	if err != nil {
+		t.Fatalf("Import() failed: %v", err)
+	}
+	reopened, err := OpenTransactionStore(path)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("OpenTransactionStore() failed on reopening: %v", err)
+	}
+	if first.Transactions != 3 || second.Transactions != 2 {
+		t.Errorf("Imported %d and %d transactions, want 3 and 2", first.Transactions, second.Transactions)
+	}
+	if _, ok := reopened.ImportedFile("hash2"); !ok || reopened.FilesCount() != 2 {
+		t.Errorf("Reopened store doesn't have imported files")
+	}
+	transactions := reopened.Transactions()
+	if len(transactions) != 5 {
+		t.Fatalf("Reopened store has %d transactions, want 5", len(transactions))
+	}
+	actual := transactions[0]
+	if actual.IsExpense != coffee.IsExpense || !actual.Date.Equal(coffee.Date) || actual.Details != coffee.Details ||
+		actual.Amount != coffee.Amount || actual.Account != coffee.Account || actual.Currency != coffee.Currency ||
+		actual.Source != coffee.Source {
+		t.Errorf("Reopened store has %+v transaction, want %+v", actual, coffee)
+	}
+	statements := reopened.Statements()
+	if len(statements) != 1 || statements[0].OpeningBalance != statement.OpeningBalance {
+		t.Errorf("Reopened store has %+v statements, want only %+v", statements, statement)
+	}
+}
+
+func TestOpenTransactionStore_InterruptedImport(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "store.jsonl")
+	content := `{"type":"transaction","hash":"1","transaction":{"isExpense":true,"date":"2024-03-01T00:00:00Z","details":"A","amount":"1.00"}}
+{"type":"file","file":{"hash":"1","name":"1.xml","importedAt":"2024-03-02T00:00:00Z","transactions":1}}
+{"type":"transaction","hash":"2","transaction":{"isExpense":true,"date":"2024-03-01T00:00:00Z","details":"B","amount":"2.00"}}
+{"type":"transaction","hash":"2","transa`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	store, err := OpenTransactionStore(path)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("OpenTransactionStore() failed: %v", err)
+	}
+	transactions := store.Transactions()
+	if len(transactions) != 1 || transactions[0].Details != "A" || transactions[0].Amount.int != 10000 {
+		t.Errorf("Expected only transaction of imported file, got %+v", transactions)
+	}
+	if _, ok := store.ImportedFile("2"); ok {
+		t.Errorf("Not finished import shouldn't be in store")
+	}
+}
+
+func TestParseAllTransactions_Store(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"1.xml": inecoValidStatement,
+		"2.xml": inecoValidStatement, // The same statement downloaded twice.
+	})
+	config := &Config{Sources: []Source{{Type: SourceTypeInecobankXml, Globs: []string{filepath.Join(dir, "*.xml")}}}}
+	storePath := filepath.Join(dir, "store.jsonl")
+	store, err := OpenTransactionStore(storePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, _, diagnostics, err := parseAllTransactions(config, store, true)
+	if err != nil {
+		t.Fatalf("parseAllTransactions() failed: %v", err)
+	}
+	for _, name := range []string{"1.xml", "2.xml"} {
+		if err := os.Remove(filepath.Join(dir, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	store, err = OpenTransactionStore(storePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	second, statements, _, err := parseAllTransactions(config, store, true)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("parseAllTransactions() failed without files: %v", err)
+	}
+	if len(first) != 2 || len(second) != 2 {
+		t.Errorf("Expected 2 transactions in both runs, got %d and %d", len(first), len(second))
+	}
+	if len(diagnostics) != 0 {
+		t.Errorf("Expected no diagnostics because the same file is skipped, got %v", diagnostics)
+	}
+	if len(statements) != 1 {
+		t.Errorf("Expected statement from store, got %v", statements)
+	}
+}
+
+func TestParseAllTransactions_StoreWithoutImport(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"1.xml": inecoValidStatement})
+	config := &Config{Sources: []Source{{Type: SourceTypeInecobankXml, Globs: []string{filepath.Join(dir, "*.xml")}}}}
+	storePath := filepath.Join(dir, "store.jsonl")
+	store, err := OpenTransactionStore(storePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	transactions, _, _, err := parseAllTransactions(config, store, false)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("parseAllTransactions() failed: %v", err)
+	}
+	if len(transactions) != 2 {
+		t.Errorf("Expected 2 transactions, got %d", len(transactions))
+	}
+	if _, err := os.Stat(storePath); !os.IsNotExist(err) {
+		t.Errorf("Store file shouldn't be written, got %v", err)
+	}
+}
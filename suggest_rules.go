@@ -0,0 +1,234 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	// suggestMinClusterSize is a minimal number of transactions to suggest a rule for.
+	suggestMinClusterSize = 2
+	// suggestMinPatternLength is a minimal length of substring to suggest as a rule.
+	suggestMinPatternLength = 4
+	// suggestMaxRules is a maximal number of rules to suggest.
+	suggestMaxRules = 50
+)
+
+// RuleSuggestion is a candidate rule for a cluster of "unknown" transactions.
+type RuleSuggestion struct {
+	// Pattern is a substring of `Transaction.Details` common for all transactions in cluster.
+	// May be empty if cluster is found by receiver account only.
+	Pattern         string
+	ReceiverAccount string
+	Reason          string
+	Transactions    []Transaction
+	Total           MoneyWith2DecimalPlaces
+}
+
+// clusterCandidate is a way to select transactions for the cluster.
+type clusterCandidate struct {
+	pattern         string
+	receiverAccount string
+	reason          string
+}
+
+func (c clusterCandidate) matches(t Transaction) bool {
+	if c.receiverAccount != "" {
+		return t.ReceiverAccount == c.receiverAccount
+	}
+	return strings.Contains(t.Details, c.pattern)
+}
+
+// detailsWords returns words from `Transaction.Details` without surrounding punctuation
+// and words without letters, i.e. numbers of orders, dates, etc.
+func detailsWords(details string) []string {
+	result := []string{}
+	for _, word := range strings.Fields(details) {
+		word = strings.TrimFunc(word, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		if strings.IndexFunc(word, unicode.IsLetter) < 0 {
+			continue
+		}
+		result = append(result, word)
+	}
+	return result
+}
+
+// commonPrefix returns the longest common prefix of strings trimmed to the last whole word.
+func commonPrefix(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	prefix := values[0]
+	for _, value := range values[1:] {
+		for !strings.HasPrefix(value, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	if len(prefix) < len(values[0]) {
+		if lastSpace := strings.LastIndex(prefix, " "); lastSpace > 0 {
+			prefix = prefix[:lastSpace]
+		}
+	}
+	return strings.TrimRightFunc(prefix, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// SuggestRules clusters transactions by common words of `Transaction.Details`, common prefix of
+// `Transaction.Details` and `Transaction.ReceiverAccount`. Then greedily picks clusters with
+// the most transactions, so each transaction belongs to only one suggested rule.
+func SuggestRules(transactions []Transaction) []RuleSuggestion {
+
+	// Collect candidates.
+	candidates := map[clusterCandidate]bool{}
+	byFirstWord := map[string][]string{}
+	for _, t := range transactions {
+		words := detailsWords(t.Details)
+		for i, word := range words {
+			candidates[clusterCandidate{pattern: word, reason: "word"}] = true
+			if i > 0 {
+				candidates[clusterCandidate{pattern: words[i-1] + " " + word, reason: "words"}] = true
+			}
+		}
+		if len(words) > 0 {
+			byFirstWord[words[0]] = append(byFirstWord[words[0]], t.Details)
+		}
+		if t.ReceiverAccount != "" {
+			candidates[clusterCandidate{receiverAccount: t.ReceiverAccount, reason: "receiver account"}] = true
+		}
+	}
+	for _, details := range byFirstWord {
+		if len(details) >= suggestMinClusterSize {
+			candidates[clusterCandidate{pattern: commonPrefix(details), reason: "prefix"}] = true
+		}
+	}
+
+	// Find transactions matched by each candidate once.
+	matched := map[clusterCandidate][]int{}
+	for candidate := range candidates {
+		if candidate.receiverAccount == "" && len([]rune(candidate.pattern)) < suggestMinPatternLength {
+			continue
+		}
+		for i, t := range transactions {
+			if candidate.matches(t) {
+				matched[candidate] = append(matched[candidate], i)
+			}
+		}
+	}
+
+	// Greedily choose the biggest clusters. Prefer longer patterns for the same clusters.
+	isClustered := make([]bool, len(transactions))
+	result := []RuleSuggestion{}
+	for len(result) < suggestMaxRules {
+		var best *RuleSuggestion
+		var bestIndexes []int
+		for candidate, indexes := range matched {
+			suggestion := RuleSuggestion{
+				Pattern:         candidate.pattern,
+				ReceiverAccount: candidate.receiverAccount,
+				Reason:          candidate.reason,
+			}
+			var suggestionIndexes []int
+			for _, i := range indexes {
+				if !isClustered[i] {
+					suggestion.Transactions = append(suggestion.Transactions, transactions[i])
+					suggestion.Total.int += transactions[i].Amount.int
+					suggestionIndexes = append(suggestionIndexes, i)
+				}
+			}
+			if len(suggestion.Transactions) < suggestMinClusterSize {
+				continue
+			}
+			if best == nil || isBetterSuggestion(suggestion, *best) {
+				best = &suggestion
+				bestIndexes = suggestionIndexes
+			}
+		}
+		if best == nil {
+			break
+		}
+
+		// For clusters by receiver account try to find common substring in details.
+		if best.ReceiverAccount != "" {
+			details := make([]string, len(best.Transactions))
+			for i, t := range best.Transactions {
+				details[i] = t.Details
+			}
+			if prefix := commonPrefix(details); len([]rune(prefix)) >= suggestMinPatternLength {
+				best.Pattern = prefix
+			}
+		}
+		result = append(result, *best)
+		for _, i := range bestIndexes {
+			isClustered[i] = true
+		}
+	}
+	return result
+}
+
+func isBetterSuggestion(a, b RuleSuggestion) bool {
+	if len(a.Transactions) != len(b.Transactions) {
+		return len(a.Transactions) > len(b.Transactions)
+	}
+	// Prefer rules by details because they may be pasted into configuration.
+	if (a.ReceiverAccount == "") != (b.ReceiverAccount == "") {
+		return a.ReceiverAccount == ""
+	}
+	if len(a.Pattern) != len(b.Pattern) {
+		return len(a.Pattern) > len(b.Pattern)
+	}
+	if a.Total.int != b.Total.int {
+		return a.Total.int > b.Total.int
+	}
+	if a.Pattern+a.ReceiverAccount != b.Pattern+b.ReceiverAccount {
+		return a.Pattern+a.ReceiverAccount < b.Pattern+b.ReceiverAccount
+	}
+	return a.Reason < b.Reason
+}
+
+// RuleSuggestionsToYaml converts suggestions into YAML ready to paste into 'groupNamesToSubstrings'.
+func RuleSuggestionsToYaml(suggestions []RuleSuggestion) string {
+	if len(suggestions) == 0 {
+		return "# There are no similar 'unknown' transactions to suggest rules for.\n"
+	}
+	var sb strings.Builder
+	sb.WriteString("groupNamesToSubstrings:\n")
+	sorted := make([]RuleSuggestion, len(suggestions))
+	copy(sorted, suggestions)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Total.int > sorted[j].Total.int
+	})
+	for _, s := range sorted {
+		if s.ReceiverAccount != "" {
+			fmt.Fprintf(&sb, "  # %d transactions totalling %s have receiver account %s",
+				len(s.Transactions), strings.TrimSpace(s.Total.String()), s.ReceiverAccount)
+		} else {
+			fmt.Fprintf(&sb, "  # %d transactions totalling %s share %q (by %s)",
+				len(s.Transactions), strings.TrimSpace(s.Total.String()), s.Pattern, s.Reason)
+		}
+		fmt.Fprintf(&sb, ", e.g. %q.\n", s.Transactions[0].Details)
+		if s.Pattern == "" {
+			sb.WriteString("  # No common substring in details, use 'accountRules' or choose substrings manually.\n")
+			continue
+		}
+		fmt.Fprintf(&sb, "  %q:\n    - %q\n", s.Pattern, s.Pattern)
+	}
+	return sb.String()
+}
+
+// collectUnknownTransactions returns transactions from `UnknownGroupName` groups of all intervals.
+func collectUnknownTransactions(statistics []*IntervalStatistic) []Transaction {
+	result := []Transaction{}
+	for _, s := range statistics {
+		for _, mapOfGroups := range []map[string]*Group{s.Income, s.Expense} {
+			if group, exists := mapOfGroups[UnknownGroupName]; exists {
+				result = append(result, group.Transactions...)
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSuggestRules(t *testing.T) {
+	// Arrange
+	transactions := []Transaction{
+		{IsExpense: true, Details: "SAS SUPERMARKET ARABKIR 0012", Amount: MoneyWith2DecimalPlaces{10000}},
+		{IsExpense: true, Details: "SAS SUPERMARKET CENTER 0013", Amount: MoneyWith2DecimalPlaces{20000}},
+		{IsExpense: true, Details: "SAS SUPERMARKET KOMITAS", Amount: MoneyWith2DecimalPlaces{30000}},
+		{IsExpense: true, Details: "Transfer 1", Amount: MoneyWith2DecimalPlaces{100000}, ReceiverAccount: "111"},
+		{IsExpense: true, Details: "Payment 2", Amount: MoneyWith2DecimalPlaces{200000}, ReceiverAccount: "111"},
+		{IsExpense: true, Details: "UNIQUE SHOP", Amount: MoneyWith2DecimalPlaces{5000}},
+	}
+
+	// Act
+	suggestions := SuggestRules(transactions)
+
+	// Assert
+	if len(suggestions) != 2 {
+		t.Fatalf("Expected 2 suggestions, got %+v", suggestions)
+	}
+	if suggestions[0].Pattern != "SAS SUPERMARKET" || len(suggestions[0].Transactions) != 3 ||
+		suggestions[0].Total.int != 60000 {
+		t.Errorf("Expected 3 transactions with 'SAS SUPERMARKET', got %+v", suggestions[0])
+	}
+	if suggestions[1].ReceiverAccount != "111" || suggestions[1].Pattern != "" ||
+		len(suggestions[1].Transactions) != 2 {
+		t.Errorf("Expected 2 transactions to '111' account, got %+v", suggestions[1])
+	}
+	yaml := RuleSuggestionsToYaml(suggestions)
+	for _, expected := range []string{
+		"# 3 transactions totalling 6.00 share \"SAS SUPERMARKET\"",
+		"  \"SAS SUPERMARKET\":\n    - \"SAS SUPERMARKET\"\n",
+		"have receiver account 111",
+	} {
+		if !strings.Contains(yaml, expected) {
+			t.Errorf("Expected '%s' in YAML:\n%s", expected, yaml)
+		}
+	}
+}